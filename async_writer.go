@@ -0,0 +1,215 @@
+package gofile
+
+import (
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueFull is returned by AsyncWriter.Write in OverflowError mode.
+var ErrQueueFull = errors.New("async writer queue full")
+
+// OverflowMode tells an AsyncWriter what to do with writes while its queue is
+// full.
+type OverflowMode int
+
+const (
+	// OverflowBlock blocks the caller until the queue has room.
+	OverflowBlock OverflowMode = iota
+	// OverflowDropNewest discards the write being made.
+	OverflowDropNewest
+	// OverflowDropOldest discards the oldest queued write to make room.
+	OverflowDropOldest
+	// OverflowError makes Write return ErrQueueFull.
+	OverflowError
+)
+
+// AsyncWriter queues writes on a bounded channel drained by a dedicated
+// goroutine, callers never wait for the underlying manager unless the
+// OverflowBlock queue is full. AsyncWriter is threadsafe.
+type AsyncWriter struct {
+	m            contracts.FileManager
+	mode         OverflowMode
+	queue        chan []byte
+	closeMtx     *sync.RWMutex
+	closed       bool
+	ioMtx        *sync.Mutex
+	mtx          *sync.Mutex
+	cnd          *sync.Cond
+	pending      int
+	errorHandler ErrorHandler
+	dropped      uint64
+	writtenBytes uint64
+	done         chan bool
+}
+
+// NewAsyncWriter starts draining a queue of queueSize writes into m.
+func NewAsyncWriter(m contracts.FileManager, queueSize int, mode OverflowMode) *AsyncWriter {
+	mtx := &sync.Mutex{}
+
+	aw := &AsyncWriter{
+		m:        m,
+		mode:     mode,
+		queue:    make(chan []byte, queueSize),
+		closeMtx: &sync.RWMutex{},
+		ioMtx:    &sync.Mutex{},
+		mtx:      mtx,
+		cnd:      sync.NewCond(mtx),
+		done:     make(chan bool),
+	}
+
+	go aw.drain()
+
+	return aw
+}
+
+// WithErrorHandler sets the handler receiving the errors of the queued
+// writes, which cannot be returned to their callers.
+func (aw *AsyncWriter) WithErrorHandler(h ErrorHandler) {
+	aw.ioMtx.Lock()
+	defer aw.ioMtx.Unlock()
+
+	aw.errorHandler = h
+}
+
+// Write queues a copy of b. Dropped writes are counted and reported as
+// successful.
+func (aw *AsyncWriter) Write(b []byte) (int, error) {
+	aw.closeMtx.RLock()
+	defer aw.closeMtx.RUnlock()
+
+	if aw.closed {
+		return 0, errors.New("async writer closed")
+	}
+
+	c := make([]byte, len(b))
+	copy(c, b)
+	aw.addPending(1)
+
+	switch aw.mode {
+	case OverflowBlock:
+		aw.queue <- c
+		return len(b), nil
+	case OverflowDropOldest:
+		for {
+			select {
+			case aw.queue <- c:
+				return len(b), nil
+			default:
+			}
+
+			select {
+			case <-aw.queue:
+				aw.drop()
+			default:
+			}
+		}
+	}
+
+	select {
+	case aw.queue <- c:
+		return len(b), nil
+	default:
+	}
+
+	if aw.mode == OverflowError {
+		aw.addPending(-1)
+		return 0, ErrQueueFull
+	}
+
+	aw.drop()
+
+	return len(b), nil
+}
+
+// WrittenBytes returns the bytes written to the underlying manager so far.
+func (aw *AsyncWriter) WrittenBytes() uint64 {
+	return atomic.LoadUint64(&aw.writtenBytes)
+}
+
+// Dropped returns the number of writes discarded because the queue was full.
+func (aw *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&aw.dropped)
+}
+
+// Flush waits for the queued writes and flushes the underlying manager.
+func (aw *AsyncWriter) Flush() error {
+	aw.waitPending()
+
+	aw.ioMtx.Lock()
+	defer aw.ioMtx.Unlock()
+
+	return aw.m.Flush()
+}
+
+// Sync waits for the queued writes and syncs the underlying manager.
+func (aw *AsyncWriter) Sync() error {
+	aw.waitPending()
+
+	aw.ioMtx.Lock()
+	defer aw.ioMtx.Unlock()
+
+	return aw.m.Sync()
+}
+
+// Close writes the queued writes then closes the underlying manager.
+func (aw *AsyncWriter) Close() error {
+	aw.closeMtx.Lock()
+
+	if aw.closed {
+		aw.closeMtx.Unlock()
+		return nil
+	}
+
+	aw.closed = true
+	close(aw.queue)
+	aw.closeMtx.Unlock()
+
+	<-aw.done
+
+	if err := aw.m.Close(); err != nil {
+		return errors.Wrap(err, "unable to close manager")
+	}
+
+	return nil
+}
+
+func (aw *AsyncWriter) drain() {
+	for b := range aw.queue {
+		aw.ioMtx.Lock()
+		w, err := aw.m.Write(b)
+		atomic.AddUint64(&aw.writtenBytes, uint64(w))
+
+		if err != nil && aw.errorHandler != nil {
+			aw.errorHandler(errors.Wrap(err, "unable to write to manager"))
+		}
+
+		aw.ioMtx.Unlock()
+		aw.addPending(-1)
+	}
+
+	aw.done <- true
+}
+
+func (aw *AsyncWriter) drop() {
+	atomic.AddUint64(&aw.dropped, 1)
+	aw.addPending(-1)
+}
+
+func (aw *AsyncWriter) addPending(n int) {
+	aw.mtx.Lock()
+	aw.pending += n
+	aw.cnd.Broadcast()
+	aw.mtx.Unlock()
+}
+
+// waitPending waits for the queued writes to be written or dropped.
+func (aw *AsyncWriter) waitPending() {
+	aw.mtx.Lock()
+	defer aw.mtx.Unlock()
+
+	for aw.pending > 0 {
+		aw.cnd.Wait()
+	}
+}
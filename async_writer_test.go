@@ -0,0 +1,171 @@
+package gofile
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncWriterWrites(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn)
+	aw := NewAsyncWriter(m, 10, OverflowBlock)
+
+	for i := 0; i < 100; i++ {
+		_, _ = aw.Write([]byte("hello"))
+	}
+
+	err := aw.Close()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Len(t, c, 500)
+	assert.Equal(t, uint64(500), aw.WrittenBytes())
+}
+
+func TestAsyncWriterCopiesWrites(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn)
+	aw := NewAsyncWriter(m, 10, OverflowBlock)
+	b := []byte("hello")
+
+	_, _ = aw.Write(b)
+	copy(b, "world")
+	aw.Close()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.Equal(t, "hello", string(c))
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	tests := []struct {
+		mode    OverflowMode
+		content string
+		dropped uint64
+		err     error
+	}{
+		{OverflowDropNewest, "ab", 1, nil},
+		{OverflowDropOldest, "ac", 1, nil},
+		{OverflowError, "ab", 0, ErrQueueFull},
+	}
+
+	for _, test := range tests {
+		fn := newFileName(t)
+		bm := newBlockingManager(t, fn)
+		aw := NewAsyncWriter(bm, 1, test.mode)
+
+		_, _ = aw.Write([]byte("a"))
+		<-bm.started
+		_, _ = aw.Write([]byte("b"))
+		_, err := aw.Write([]byte("c"))
+		close(bm.release)
+		aw.Close()
+		c, _ := ioutil.ReadFile(fn)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.content, string(c))
+		assert.Equal(t, test.dropped, aw.Dropped())
+	}
+}
+
+func TestAsyncWriterBlocksWhenFull(t *testing.T) {
+	fn := newFileName(t)
+	bm := newBlockingManager(t, fn)
+	aw := NewAsyncWriter(bm, 1, OverflowBlock)
+	written := make(chan struct{})
+
+	_, _ = aw.Write([]byte("a"))
+	<-bm.started
+	_, _ = aw.Write([]byte("b"))
+
+	go func() {
+		_, _ = aw.Write([]byte("c"))
+		close(written)
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write did not block")
+	default:
+	}
+
+	close(bm.release)
+	<-written
+	aw.Close()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.Equal(t, "abc", string(c))
+	assert.Equal(t, uint64(0), aw.Dropped())
+}
+
+func TestAsyncWriterFlushWaitsForQueue(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn)
+	aw := NewAsyncWriter(m, 10, OverflowBlock)
+	defer aw.Close()
+
+	for i := 0; i < 10; i++ {
+		_, _ = aw.Write([]byte("hello"))
+	}
+
+	err := aw.Flush()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Len(t, c, 50)
+}
+
+func TestAsyncWriterReportsErrors(t *testing.T) {
+	var reported error
+	m, _ := NewManager(newFileName(t))
+	m.Close()
+	aw := NewAsyncWriter(m, 10, OverflowBlock)
+	aw.WithErrorHandler(func(err error) {
+		reported = err
+	})
+
+	_, err := aw.Write([]byte("hello"))
+	aw.Close()
+
+	assert.NoError(t, err)
+	assert.Error(t, reported)
+}
+
+func TestAsyncWriterWriteAfterClose(t *testing.T) {
+	m, _ := NewManager(newFileName(t))
+	aw := NewAsyncWriter(m, 10, OverflowBlock)
+
+	aw.Close()
+	_, err := aw.Write([]byte("hello"))
+
+	assert.Error(t, err)
+	assert.NoError(t, aw.Close())
+}
+
+type blockingManager struct {
+	contracts.FileManager
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingManager(t *testing.T, fn string) *blockingManager {
+	m, err := NewManager(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &blockingManager{
+		FileManager: m,
+		started:     make(chan struct{}, 10),
+		release:     make(chan struct{}),
+	}
+}
+
+func (m *blockingManager) Write(b []byte) (int, error) {
+	m.started <- struct{}{}
+	<-m.release
+
+	return m.FileManager.Write(b)
+}
@@ -0,0 +1,41 @@
+package gofile
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultBackoff is used when no other Backoff has been configured.
+var DefaultBackoff = Backoff{
+	Initial: 100 * time.Millisecond,
+	Max:     30 * time.Second,
+}
+
+// Backoff computes exponentially growing delays between retries.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Duration returns the delay to wait before the given retry attempt, attempts
+// start at 0.
+func (b Backoff) Duration(attempt int) time.Duration {
+	d := b.Initial
+	if d <= 0 {
+		return 0
+	}
+
+	for i := 0; i < attempt && d < math.MaxInt64/2; i++ {
+		d = d * 2
+
+		if b.Max > 0 && d >= b.Max {
+			return b.Max
+		}
+	}
+
+	if b.Max > 0 && d > b.Max {
+		return b.Max
+	}
+
+	return d
+}
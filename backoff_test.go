@@ -0,0 +1,27 @@
+package gofile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDoubles(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: time.Minute}
+
+	assert.Equal(t, time.Second, b.Duration(0))
+	assert.Equal(t, 2*time.Second, b.Duration(1))
+	assert.Equal(t, 4*time.Second, b.Duration(2))
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 3 * time.Second}
+
+	assert.Equal(t, 3*time.Second, b.Duration(2))
+	assert.Equal(t, 3*time.Second, b.Duration(100))
+}
+
+func TestZeroBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Backoff{}.Duration(3))
+}
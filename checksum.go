@@ -0,0 +1,189 @@
+package gofile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+)
+
+// ChecksumAlgorithm is a hash function used to checksum files, Name is used
+// as the extension of the sidecars.
+type ChecksumAlgorithm struct {
+	Name string
+	New  func() hash.Hash
+}
+
+var (
+	CRC32C = ChecksumAlgorithm{Name: "crc32c", New: func() hash.Hash {
+		return crc32.New(castagnoli)
+	}}
+	SHA256   = ChecksumAlgorithm{Name: "sha256", New: sha256.New}
+	XXHash64 = ChecksumAlgorithm{Name: "xxh64", New: func() hash.Hash {
+		return xxhash.New()
+	}}
+)
+
+// Checksum is the digest of a file as stored on disk.
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// String returns the hex encoded digest.
+func (c Checksum) String() string {
+	return hex.EncodeToString(c.Sum)
+}
+
+// WithChecksum hashes the bytes written to the file. Stream compression and
+// hooks included, the Checksum matches the file on disk once closed.
+func WithChecksum(alg ChecksumAlgorithm) ManagerOption {
+	return func(m *Manager) {
+		m.checksum = alg
+	}
+}
+
+// Checksum returns the digest of the bytes written so far, it is final once
+// the Manager is closed. It is zero without WithChecksum.
+func (m *Manager) Checksum() Checksum {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.digest()
+}
+
+func (m *Manager) digest() Checksum {
+	if m.disk.h == nil {
+		return Checksum{}
+	}
+
+	return Checksum{Algorithm: m.checksum.Name, Sum: m.disk.h.Sum(nil)}
+}
+
+// checksummer is implemented by managers computing the checksum of their
+// file.
+type checksummer interface {
+	Checksum() Checksum
+}
+
+// SidecarFormat is the format of the checksum sidecars.
+type SidecarFormat int
+
+const (
+	// SumSidecar writes path.<algorithm> in the format of sha256sum and the
+	// like, so that it can be checked with sha256sum -c.
+	SumSidecar SidecarFormat = iota
+	// JSONSidecar writes path.<algorithm>.json holding the file name, the
+	// algorithm, the checksum and the size.
+	JSONSidecar
+)
+
+// WithChecksumSidecar checksums the files of the default factory with alg and
+// writes a sidecar next to each rotated file before handing it over. With
+// WithCompression the sidecar describes the compressed file. Sidecars are
+// removed along with their file by the retention.
+func WithChecksumSidecar(alg ChecksumAlgorithm, format SidecarFormat) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.managerOptions = append(rm.managerOptions, WithChecksum(alg))
+		rm.sidecar = &sidecar{alg: alg, ext: "." + alg.Name, format: format}
+
+		if format == JSONSidecar {
+			rm.sidecar.ext += ".json"
+		}
+	}
+}
+
+type sidecar struct {
+	alg    ChecksumAlgorithm
+	ext    string
+	format SidecarFormat
+}
+
+type jsonSidecar struct {
+	File      string `json:"file"`
+	Algorithm string `json:"algorithm"`
+	Checksum  string `json:"checksum"`
+	Size      int64  `json:"size"`
+}
+
+// writeSidecar writes the sidecar of a finalized file.
+func (rm *RotatingManager) writeSidecar(f RotatedFile) {
+	if rm.sidecar == nil || f.Checksum.Algorithm == "" {
+		return
+	}
+
+	if err := rm.sidecar.write(f.Path, f.Checksum); err != nil {
+		rm.reportError(&RotationError{Op: "checksum", Path: f.Path, Err: err})
+	}
+}
+
+func (s *sidecar) write(path string, sum Checksum) error {
+	var content []byte
+
+	switch s.format {
+	case JSONSidecar:
+		info, err := os.Stat(path)
+		if err != nil {
+			return errors.Wrap(err, "unable to stat file")
+		}
+
+		content, err = json.Marshal(jsonSidecar{
+			File:      filepath.Base(path),
+			Algorithm: sum.Algorithm,
+			Checksum:  sum.String(),
+			Size:      info.Size(),
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to encode sidecar")
+		}
+
+		content = append(content, '\n')
+	default:
+		content = []byte(fmt.Sprintf("%s  %s\n", sum, filepath.Base(path)))
+	}
+
+	if err := ioutil.WriteFile(path+s.ext, content, 0644); err != nil {
+		return errors.Wrap(err, "unable to write sidecar")
+	}
+
+	return syncFile(path + s.ext)
+}
+
+func (rm *RotatingManager) isSidecar(path string) bool {
+	return rm.sidecar != nil && strings.HasSuffix(path, rm.sidecar.ext)
+}
+
+// removeSidecar removes the sidecar of a file deleted by the retention.
+func (rm *RotatingManager) removeSidecar(path string) {
+	if rm.sidecar == nil {
+		return
+	}
+
+	_ = os.Remove(path + rm.sidecar.ext)
+}
+
+// checksumAlgorithm finds the algorithm named name, the one of the sidecars
+// first and then the builtin ones.
+func (rm *RotatingManager) checksumAlgorithm(name string) (ChecksumAlgorithm, bool) {
+	var algs []ChecksumAlgorithm
+	if rm.sidecar != nil {
+		algs = append(algs, rm.sidecar.alg)
+	}
+
+	for _, alg := range append(algs, CRC32C, SHA256, XXHash64) {
+		if name != "" && alg.Name == name {
+			return alg, true
+		}
+	}
+
+	return ChecksumAlgorithm{}, false
+}
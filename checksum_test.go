@@ -0,0 +1,166 @@
+package gofile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerChecksum(t *testing.T) {
+	tests := []struct {
+		alg  ChecksumAlgorithm
+		want string
+	}{
+		{SHA256, fmt.Sprintf("%x", sha256.Sum256([]byte("hello world")))},
+		{CRC32C, fmt.Sprintf("%08x", crc32.Checksum([]byte("hello world"), castagnoli))},
+		{XXHash64, fmt.Sprintf("%016x", xxhash.Sum64String("hello world"))},
+	}
+
+	for _, test := range tests {
+		m, _ := NewManager(newFileName(t), WithChecksum(test.alg))
+
+		_, _ = m.Write([]byte("hello "))
+		_, _ = m.Write([]byte("world"))
+		m.Close()
+
+		assert.Equal(t, test.alg.Name, m.Checksum().Algorithm)
+		assert.Equal(t, test.want, m.Checksum().String())
+	}
+}
+
+func TestManagerChecksumCoversCompressedBytes(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn, WithStreamCompression(Gzip, 0), WithChecksum(SHA256))
+
+	_, _ = m.Write([]byte("hello"))
+	m.Close()
+	c, _ := ioutil.ReadFile(fn)
+	want := sha256.Sum256(c)
+
+	assert.Equal(t, want[:], m.Checksum().Sum)
+}
+
+func TestManagerWithoutChecksum(t *testing.T) {
+	m, _ := NewManager(newFileName(t))
+
+	_, _ = m.Write([]byte("hello"))
+	m.Close()
+
+	assert.Equal(t, Checksum{}, m.Checksum())
+}
+
+func TestSumSidecar(t *testing.T) {
+	var rotated string
+	dir := t.TempDir()
+	rm, _ := NewRotatingManagerWithPolicy(
+		dir, "events_", CountPolicy(1), nil,
+		WithChecksumSidecar(SHA256, SumSidecar),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			rotated = f.Path
+		}),
+	)
+	defer rm.Close()
+
+	_, _ = rm.Write([]byte("hello"))
+	c, err := ioutil.ReadFile(rotated + ".sha256")
+	want := sha256.Sum256([]byte("hello"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(want[:])+"  "+filepath.Base(rotated)+"\n", string(c))
+}
+
+func TestJSONSidecar(t *testing.T) {
+	var rotated string
+	dir := t.TempDir()
+	rm, _ := NewRotatingManagerWithPolicy(
+		dir, "events_", CountPolicy(1), nil,
+		WithChecksumSidecar(XXHash64, JSONSidecar),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			rotated = f.Path
+		}),
+	)
+	defer rm.Close()
+
+	_, _ = rm.Write([]byte("hello"))
+	c, err := ioutil.ReadFile(rotated + ".xxh64.json")
+	var s jsonSidecar
+	_ = json.Unmarshal(c, &s)
+
+	assert.NoError(t, err)
+	assert.Equal(t, jsonSidecar{
+		File:      filepath.Base(rotated),
+		Algorithm: "xxh64",
+		Checksum:  fmt.Sprintf("%016x", xxhash.Sum64String("hello")),
+		Size:      5,
+	}, s)
+}
+
+func TestSidecarsAreRemovedByRetention(t *testing.T) {
+	dir := t.TempDir()
+	rm, _ := NewRotatingManagerWithPolicy(
+		dir, "events_", CountPolicy(1), nil,
+		WithChecksumSidecar(SHA256, SumSidecar),
+		WithRetention(Retention{MaxFiles: 1}),
+	)
+
+	for i := 0; i < 5; i++ {
+		_, _ = rm.Write([]byte("hello"))
+	}
+
+	rm.Close()
+	files, _ := ioutil.ReadDir(dir)
+
+	assert.Len(t, files, 2)
+}
+
+func TestRotatedFileChecksum(t *testing.T) {
+	var rotated RotatedFile
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithManagerOptions(WithChecksum(SHA256)),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			rotated = f
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+	want := sha256.Sum256([]byte("hello"))
+
+	assert.Equal(t, "sha256", rotated.Checksum.Algorithm)
+	assert.Equal(t, want[:], rotated.Checksum.Sum)
+}
+
+func TestSidecarDescribesCompressedFile(t *testing.T) {
+	handled := make(chan RotatedFile, 1)
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithChecksumSidecar(SHA256, SumSidecar),
+		WithCompression(Compression{Codec: Gzip}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+	rotated := <-handled
+	compressed, _ := ioutil.ReadFile(rotated.Path)
+	want := sha256.Sum256(compressed)
+	c, err := ioutil.ReadFile(rotated.Path + ".sha256")
+
+	assert.Equal(t, want[:], rotated.Checksum.Sum)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(want[:])+"  "+filepath.Base(rotated.Path)+"\n", string(c))
+	assert.NoFileExists(t, strings.TrimSuffix(rotated.Path, ".gz")+".sha256")
+}
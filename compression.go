@@ -0,0 +1,281 @@
+package gofile
+
+import (
+	"compress/gzip"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Codec compresses and decompresses files.
+type Codec interface {
+	// Extension is appended to the name of compressed files, ".gz".
+	Extension() string
+	// NewWriter compresses to w, level 0 uses the codec default level.
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	// Gzip levels go from 1 (best speed) to 9 (best compression).
+	Gzip Codec = gzipCodec{}
+
+	// Zstd levels follow the zstd command line, from 1 to 22.
+	Zstd Codec = zstdCodec{}
+
+	// Snappy uses the snappy framing format, levels are ignored.
+	Snappy Codec = snappyCodec{}
+)
+
+type gzipCodec struct{}
+
+func (gzipCodec) Extension() string {
+	return ".gz"
+}
+
+func (gzipCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	return gzip.NewWriterLevel(w, level)
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+type zstdCodec struct{}
+
+func (zstdCodec) Extension() string {
+	return ".zst"
+}
+
+func (zstdCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	l := zstd.SpeedDefault
+	if level != 0 {
+		l = zstd.EncoderLevelFromZstd(level)
+	}
+
+	return zstd.NewWriter(w, zstd.WithEncoderLevel(l))
+}
+
+func (zstdCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	d, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.IOReadCloser(), nil
+}
+
+type snappyCodec struct{}
+
+func (snappyCodec) Extension() string {
+	return ".sz"
+}
+
+func (snappyCodec) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) {
+	return snappy.NewBufferedWriter(w), nil
+}
+
+func (snappyCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return ioutil.NopCloser(snappy.NewReader(r)), nil
+}
+
+// Compression configures the background compression of rotated files.
+type Compression struct {
+	Codec Codec
+	// Level 0 uses the codec default level.
+	Level int
+	// Workers defaults to 1.
+	Workers int
+	// QueueSize is the number of rotated files queued for the workers, the
+	// writes rotating files wait while the queue is full. It defaults to 16.
+	QueueSize int
+}
+
+// WithCompression compresses every rotated file in the background. The
+// compressed file is verified before the original is deleted and only then
+// handed over to the RotatedFileHandler. Files that fail to compress are
+// handed over uncompressed.
+func WithCompression(c Compression) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		if c.Workers <= 0 {
+			c.Workers = 1
+		}
+
+		if c.QueueSize <= 0 {
+			c.QueueSize = 16
+		}
+
+		rm.compression = &c
+	}
+}
+
+// startCompressors starts the workers compressing rotated files.
+func (rm *RotatingManager) startCompressors() {
+	if rm.compression == nil {
+		return
+	}
+
+	rm.compressions = make(chan RotatedFile, rm.compression.QueueSize)
+	rm.compressors = &sync.WaitGroup{}
+
+	for i := 0; i < rm.compression.Workers; i++ {
+		rm.compressors.Add(1)
+
+		go func() {
+			defer rm.compressors.Done()
+
+			for f := range rm.compressions {
+				// The compressed file is kept from the retention before
+				// it even exists.
+				dst := f.Path + rm.compression.Codec.Extension()
+				rm.unhandled.add(dst)
+
+				// The checksum of the file is replaced by the one of its
+				// compressed copy.
+				var h hash.Hash
+				if alg, ok := rm.checksumAlgorithm(f.Checksum.Algorithm); ok {
+					h = alg.New()
+				}
+
+				compressed, err := compressFile(f.Path, rm.compression.Codec, rm.compression.Level, h)
+				if err != nil {
+					rm.unhandled.remove(dst)
+					rm.reportError(errors.Wrapf(err, "unable to compress %s", f.Path))
+				} else {
+					rm.journalAdd(compressed)
+					rm.journalDone(f.Path)
+					rm.unhandled.remove(f.Path)
+					f = f.compressed(compressed, h)
+				}
+
+				rm.writeSidecar(f)
+				rm.notifyRotationHandler(f)
+			}
+		}()
+	}
+}
+
+// stopCompressors waits for the queued files to be compressed.
+func (rm *RotatingManager) stopCompressors() {
+	if rm.compression == nil {
+		return
+	}
+
+	close(rm.compressions)
+	rm.compressors.Wait()
+}
+
+// CompressFile compresses path into path + the codec extension. The result is
+// synced and verified against the original, which is deleted only then.
+func CompressFile(path string, codec Codec, level int) (string, error) {
+	return compressFile(path, codec, level, nil)
+}
+
+// compressFile is CompressFile also writing the compressed bytes to h, if
+// any.
+func compressFile(path string, codec Codec, level int, h hash.Hash) (string, error) {
+	dst := path + codec.Extension()
+	tmp := dst + ".tmp"
+
+	sum, size, err := compressTo(path, tmp, codec, level, h)
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if err := verifyCompressed(tmp, codec, sum, size); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return "", errors.Wrap(err, "unable to rename compressed file")
+	}
+
+	syncDir(filepath.Dir(dst))
+
+	if err := os.Remove(path); err != nil {
+		return "", errors.Wrap(err, "unable to remove original file")
+	}
+
+	return dst, nil
+}
+
+func compressTo(src, dst string, codec Codec, level int, digest hash.Hash) (uint32, int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "unable to open file")
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "unable to create compressed file")
+	}
+	defer out.Close()
+
+	var compressed io.Writer = out
+	if digest != nil {
+		compressed = io.MultiWriter(out, digest)
+	}
+
+	w, err := codec.NewWriter(compressed, level)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "unable to create compressor")
+	}
+
+	h := crc32.NewIEEE()
+	size, err := io.Copy(w, io.TeeReader(in, h))
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "unable to compress file")
+	}
+
+	if err := w.Close(); err != nil {
+		return 0, 0, errors.Wrap(err, "unable to close compressor")
+	}
+
+	if err := out.Sync(); err != nil {
+		return 0, 0, errors.Wrap(err, "unable to sync compressed file")
+	}
+
+	return h.Sum32(), size, nil
+}
+
+func verifyCompressed(path string, codec Codec, sum uint32, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "unable to open compressed file")
+	}
+	defer f.Close()
+
+	r, err := codec.NewReader(f)
+	if err != nil {
+		return errors.Wrap(err, "unable to read compressed file")
+	}
+	defer r.Close()
+
+	h := crc32.NewIEEE()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return errors.Wrap(err, "unable to decompress file")
+	}
+
+	if n != size || h.Sum32() != sum {
+		return errors.New("compressed file does not match original")
+	}
+
+	return nil
+}
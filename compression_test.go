@@ -0,0 +1,175 @@
+package gofile
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressFile(t *testing.T) {
+	for _, codec := range []Codec{Gzip, Zstd} {
+		fn := newFileName(t)
+		content := []byte(strings.Repeat("hello\n", 1000))
+		_ = ioutil.WriteFile(fn, content, 0644)
+
+		compressed, err := CompressFile(fn, codec, 0)
+		_, statErr := os.Stat(fn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fn+codec.Extension(), compressed)
+		assert.True(t, os.IsNotExist(statErr))
+		assert.Equal(t, content, readCompressed(t, compressed, codec))
+	}
+}
+
+func TestCompressFileWithLevel(t *testing.T) {
+	for _, codec := range []Codec{Gzip, Zstd} {
+		fn := newFileName(t)
+		_ = ioutil.WriteFile(fn, []byte("hello"), 0644)
+
+		compressed, err := CompressFile(fn, codec, 9)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("hello"), readCompressed(t, compressed, codec))
+	}
+}
+
+func TestCompressFileKeepsOriginalOnError(t *testing.T) {
+	fn := newFileName(t)
+	_ = ioutil.WriteFile(fn, []byte("hello"), 0644)
+
+	_, err := CompressFile(fn, brokenCodec{}, 0)
+	_, statErr := os.Stat(fn)
+	_, tmpErr := os.Stat(fn + ".broken.tmp")
+
+	assert.Error(t, err)
+	assert.NoError(t, statErr)
+	assert.True(t, os.IsNotExist(tmpErr))
+}
+
+func TestRotatingManagerCompressesRotatedFiles(t *testing.T) {
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithCompression(Compression{Codec: Gzip}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("world"))
+	rm.Close()
+
+	p1, p2 := <-handled, <-handled
+
+	assert.True(t, strings.HasSuffix(p1, ".gz"))
+	assert.True(t, strings.HasSuffix(p2, ".gz"))
+	assert.Equal(t, "hello", string(readCompressed(t, p1, Gzip)))
+	assert.Equal(t, "world", string(readCompressed(t, p2, Gzip)))
+}
+
+func TestRotatingManagerHandsOverUncompressedOnError(t *testing.T) {
+	var reported error
+	var handled string
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithCompression(Compression{Codec: brokenCodec{}}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = f.Path
+		}),
+		WithErrorHandler(func(err error) {
+			reported = err
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	path := rm.m.path
+	rm.Close()
+
+	assert.Error(t, reported)
+	assert.Equal(t, path, handled)
+}
+
+func TestRotatingManagerCompressionErrorsDoNotBlockWrites(t *testing.T) {
+	reported := make(chan error, 20)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithCompression(Compression{Codec: brokenCodec{}, QueueSize: 1}),
+		WithErrorHandler(func(err error) {
+			reported <- err
+		}),
+	)
+
+	written := make(chan bool)
+	go func() {
+		for i := 0; i < 10; i++ {
+			_, _ = rm.Write([]byte("hello"))
+		}
+
+		rm.Close()
+		written <- true
+	}()
+
+	select {
+	case <-written:
+	case <-time.After(time.Second * 5):
+		t.Fatal("writes blocked by the failing compressions")
+	}
+
+	assert.Len(t, reported, 11)
+}
+
+func TestRotatingManagerStreamCompression(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithManagerOptions(WithStreamCompression(Zstd, 0)),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	assert.Equal(t, "hello", string(readCompressed(t, <-handled, Zstd)))
+}
+
+type brokenCodec struct{}
+
+func (brokenCodec) Extension() string {
+	return ".broken"
+}
+
+func (brokenCodec) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return nil, errors.New("I am broken")
+}
+
+func (brokenCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return nil, errors.New("I am broken")
+}
+
+func readCompressed(t *testing.T, path string, codec Codec) []byte {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	r, err := codec.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	b, _ := ioutil.ReadAll(r)
+
+	return b
+}
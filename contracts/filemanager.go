@@ -5,5 +5,9 @@ package contracts
 type FileManager interface {
 	Write(b []byte) (int, error)
 	WrittenBytes() uint64
+	// Flush writes buffered data to the underlying file.
+	Flush() error
+	// Sync flushes and commits the file to stable storage.
+	Sync() error
 	Close() error
-}
\ No newline at end of file
+}
@@ -0,0 +1,70 @@
+package gofile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"sync"
+
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+)
+
+// WithCSVHeader writes the header row at the start of every file.
+func WithCSVHeader(header []string) RotatingManagerOption {
+	return WithFileHooks(FileHooks{
+		OnOpen: func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+
+			if err := cw.Write(header); err != nil {
+				return errors.Wrap(err, "unable to write csv header")
+			}
+
+			cw.Flush()
+
+			return errors.Wrap(cw.Error(), "unable to write csv header")
+		},
+	})
+}
+
+// CSVWriter writes CSV rows, one Write per row. Use WithCSVHeader to start
+// every file with a header. CSVWriter is threadsafe when its manager is.
+type CSVWriter struct {
+	m   contracts.FileManager
+	mtx *sync.Mutex
+	buf *bytes.Buffer
+	w   *csv.Writer
+}
+
+func NewCSVWriter(m contracts.FileManager) *CSVWriter {
+	buf := &bytes.Buffer{}
+
+	return &CSVWriter{
+		m:   m,
+		mtx: &sync.Mutex{},
+		buf: buf,
+		w:   csv.NewWriter(buf),
+	}
+}
+
+// WriteRow writes a row, fields are quoted when needed.
+func (w *CSVWriter) WriteRow(row []string) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	defer w.buf.Reset()
+
+	if err := w.w.Write(row); err != nil {
+		return errors.Wrap(err, "unable to encode row")
+	}
+
+	w.w.Flush()
+	if err := w.w.Error(); err != nil {
+		return errors.Wrap(err, "unable to encode row")
+	}
+
+	if _, err := w.m.Write(w.buf.Bytes()); err != nil {
+		return errors.Wrap(err, "unable to write row")
+	}
+
+	return nil
+}
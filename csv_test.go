@@ -0,0 +1,42 @@
+package gofile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVWriterQuotesFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewCSVWriter(&bufferManager{buf: buf})
+
+	err := w.WriteRow([]string{"a", "b,c", "d\"e", "f\ng"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a,\"b,c\",\"d\"\"e\",\"f\ng\"\n", buf.String())
+}
+
+func TestCSVHeaderOnEveryFile(t *testing.T) {
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(2), nil,
+		WithCSVHeader([]string{"id", "name"}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+	w := NewCSVWriter(rm)
+
+	_ = w.WriteRow([]string{"1", "hello"})
+	_ = w.WriteRow([]string{"2", "world"})
+	_ = w.WriteRow([]string{"3", "again"})
+	rm.Close()
+
+	c1, _ := ioutil.ReadFile(<-handled)
+	c2, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "id,name\n1,hello\n2,world\n", string(c1))
+	assert.Equal(t, "id,name\n3,again\n", string(c2))
+}
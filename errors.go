@@ -0,0 +1,40 @@
+package gofile
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrDegraded is returned by RotatingManager.Write when a rotation failed and
+// the manager refuses writes until a new file could be opened.
+var ErrDegraded = errors.New("rotating manager degraded")
+
+// ErrorHandler receives the errors that cannot be returned to a caller, such
+// as a rotation failing from the ticker goroutine.
+type ErrorHandler func(err error)
+
+// RotationError describes a failed step of a rotation.
+type RotationError struct {
+	// Op is one of "open", "close", "finalize", "recover", "hook" or
+	// "checksum".
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *RotationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("rotation %s failed: %s", e.Op, e.Err)
+	}
+
+	return fmt.Sprintf("rotation %s failed for %s: %s", e.Op, e.Path, e.Err)
+}
+
+func (e *RotationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RotationError) Cause() error {
+	return e.Err
+}
@@ -0,0 +1,186 @@
+package gofile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// finalize makes a closed file visible under its final name. With an in
+// progress suffix the file is synced then atomically renamed.
+func (rm *RotatingManager) finalize(dm *decoratedManager) error {
+	if dm.activePath == dm.path {
+		return nil
+	}
+
+	if err := syncFile(dm.activePath); err != nil {
+		return err
+	}
+
+	if err := os.Rename(dm.activePath, dm.path); err != nil {
+		return errors.Wrap(err, "unable to rename file")
+	}
+
+	syncDir(filepath.Dir(dm.path))
+
+	return nil
+}
+
+func syncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return errors.Wrap(err, "unable to open file")
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to sync file")
+	}
+
+	return errors.Wrap(f.Close(), "unable to close file")
+}
+
+// syncDir persists renames, it is best effort as not every platform supports
+// syncing directories.
+func syncDir(path string) {
+	d, err := os.Open(path)
+	if err != nil {
+		return
+	}
+
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+// Repairer fixes a file left behind by a crash, typically by truncating its
+// torn trailing record.
+type Repairer func(path string) error
+
+// TruncateAfterLast truncates everything after the last delim of a file,
+// dropping a partially written trailing line when delim is '\n'.
+func TruncateAfterLast(delim byte) Repairer {
+	return func(path string) error {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return errors.Wrap(err, "unable to open file")
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return errors.Wrap(err, "unable to stat file")
+		}
+
+		size := info.Size()
+		buf := make([]byte, 32*1024)
+
+		for end := size; end > 0; {
+			start := end - int64(len(buf))
+			if start < 0 {
+				start = 0
+			}
+
+			chunk := buf[:end-start]
+			if _, err := f.ReadAt(chunk, start); err != nil {
+				return errors.Wrap(err, "unable to read file")
+			}
+
+			if i := bytes.LastIndexByte(chunk, delim); i >= 0 {
+				return truncate(f, start+int64(i)+1, size)
+			}
+
+			end = start
+		}
+
+		return truncate(f, 0, size)
+	}
+}
+
+func truncate(f *os.File, at, size int64) error {
+	if at == size {
+		return nil
+	}
+
+	return errors.Wrap(f.Truncate(at), "unable to truncate file")
+}
+
+// recoverOrphans finalizes the in progress files found in the directory, they
+// can only have been left behind by a previous process.
+func (rm *RotatingManager) recoverOrphans() error {
+	if !rm.recover {
+		return nil
+	}
+
+	if rm.inProgressSuffix == "" {
+		return errors.New("recovery requires an in progress suffix")
+	}
+
+	var orphans []string
+	err := rm.walkFiles(func(path string, info os.FileInfo) {
+		name := info.Name()
+
+		if strings.HasSuffix(name, rm.inProgressSuffix) && rm.matchName(strings.TrimSuffix(name, rm.inProgressSuffix)) {
+			orphans = append(orphans, path)
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, orphan := range orphans {
+		dm := &decoratedManager{
+			path:       strings.TrimSuffix(orphan, rm.inProgressSuffix),
+			activePath: orphan,
+		}
+
+		if err := rm.recoverOrphan(dm); err != nil {
+			rm.reportError(&RotationError{Op: "recover", Path: orphan, Err: err})
+		}
+	}
+
+	return nil
+}
+
+func (rm *RotatingManager) recoverOrphan(dm *decoratedManager) error {
+	if rm.repairer != nil {
+		if err := rm.repairer(dm.activePath); err != nil {
+			return errors.Wrap(err, "unable to repair file")
+		}
+	}
+
+	info, err := os.Stat(dm.activePath)
+	if err != nil {
+		return errors.Wrap(err, "unable to stat file")
+	}
+
+	if info.Size() == 0 {
+		return errors.Wrap(os.Remove(dm.activePath), "unable to remove empty file")
+	}
+
+	if err := rm.finalize(dm); err != nil {
+		return err
+	}
+
+	rm.handOver(recoveredFile(dm.path))
+
+	return nil
+}
+
+// matchName tells whether name may have been given by the name strategy,
+// strategies that cannot tell match every name starting with the prefix.
+func (rm *RotatingManager) matchName(name string) bool {
+	if m, ok := rm.names.(NameMatcher); ok {
+		return m.MatchName(rm.prefix, name)
+	}
+
+	if p, ok := rm.names.(SequenceParser); ok {
+		_, ok = p.ParseSeq(rm.prefix, name)
+		return ok
+	}
+
+	return strings.HasPrefix(name, rm.prefix)
+}
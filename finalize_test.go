@@ -0,0 +1,207 @@
+package gofile
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInProgressFileIsWrittenWithSuffix(t *testing.T) {
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithInProgressSuffix(".inprogress"),
+	)
+
+	_, err1 := os.Stat(rm.m.path + ".inprogress")
+	_, err2 := os.Stat(rm.m.path)
+
+	assert.NoError(t, err1)
+	assert.True(t, os.IsNotExist(err2))
+}
+
+func TestInProgressFileIsRenamedBeforeHandler(t *testing.T) {
+	var content []byte
+	var statErr error
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithInProgressSuffix(".inprogress"),
+	)
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		_, statErr = os.Stat(f.Path + ".inprogress")
+		content, _ = ioutil.ReadFile(f.Path)
+	})
+
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.True(t, os.IsNotExist(statErr))
+	assert.Equal(t, "hello", string(content))
+}
+
+func TestInProgressFileIsRenamedOnClose(t *testing.T) {
+	var rotated string
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithInProgressSuffix(".inprogress"),
+	)
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		rotated = f.Path
+	})
+
+	_, _ = rm.Write([]byte("hello"))
+	err := rm.Close()
+	content, _ := ioutil.ReadFile(rotated)
+
+	assert.NoError(t, err)
+	assert.Equal(t, rm.m.path, rotated)
+	assert.Equal(t, "hello", string(content))
+}
+
+func TestFinalizeErrorIsReported(t *testing.T) {
+	var reported error
+	var called bool
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithInProgressSuffix(".inprogress"),
+	)
+	rm.WithErrorHandler(func(err error) {
+		reported = err
+	})
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		called = true
+	})
+
+	_ = os.Remove(rm.m.activePath)
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.Error(t, reported)
+	assert.False(t, called)
+}
+
+func TestRecoveryFinalizesOrphans(t *testing.T) {
+	var handled []string
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(tmp+"/events_1.inprogress", []byte("a\nb\npart"), 0644)
+	_ = ioutil.WriteFile(tmp+"/other_1.inprogress", []byte("a\n"), 0644)
+
+	rm, err := NewRotatingManager(
+		tmp, "events_", time.Second*100, 1000,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithInProgressSuffix(".inprogress"),
+		WithRecovery(TruncateAfterLast('\n')),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = append(handled, f.Path)
+		}),
+	)
+	recovered := len(handled)
+	rm.Close()
+
+	content, _ := ioutil.ReadFile(tmp + "/events_1")
+	_, otherErr := os.Stat(tmp + "/other_1.inprogress")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	assert.Equal(t, []string{tmp + "/events_1", rm.m.path}, handled)
+	assert.Equal(t, "a\nb\n", string(content))
+	assert.NoError(t, otherErr)
+}
+
+func TestRecoveryRemovesEmptyOrphans(t *testing.T) {
+	var called bool
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(tmp+"/events_1.inprogress", []byte("torn"), 0644)
+
+	_, _ = NewRotatingManager(
+		tmp, "events_", time.Second*100, 1000,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithInProgressSuffix(".inprogress"),
+		WithRecovery(TruncateAfterLast('\n')),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			called = true
+		}),
+	)
+
+	_, err1 := os.Stat(tmp + "/events_1.inprogress")
+	_, err2 := os.Stat(tmp + "/events_1")
+
+	assert.True(t, os.IsNotExist(err1))
+	assert.True(t, os.IsNotExist(err2))
+	assert.False(t, called)
+}
+
+func TestRecoveryReportsErrors(t *testing.T) {
+	var reported error
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(tmp+"/events_1.inprogress", []byte("a\n"), 0644)
+	broken := func(path string) error {
+		return os.ErrPermission
+	}
+
+	_, err := NewRotatingManager(
+		tmp, "events_", time.Second*100, 1000,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithInProgressSuffix(".inprogress"),
+		WithRecovery(broken),
+		WithErrorHandler(func(err error) {
+			reported = err
+		}),
+	)
+
+	assert.NoError(t, err)
+	assert.Error(t, reported)
+}
+
+func TestRecoveryMatchesNameStrategy(t *testing.T) {
+	var handled []string
+	tmp := t.TempDir()
+	orphan := NewRandFileName(tmp, "events_")
+	_ = ioutil.WriteFile(orphan+".inprogress", []byte("a\n"), 0644)
+	_ = ioutil.WriteFile(tmp+"/events_other.inprogress", []byte("a\n"), 0644)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 1000,
+		WithInProgressSuffix(".inprogress"),
+		WithRecovery(nil),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = append(handled, f.Path)
+		}),
+	)
+	rm.Close()
+
+	_, otherErr := os.Stat(tmp + "/events_other.inprogress")
+
+	assert.Equal(t, []string{orphan, rm.m.path}, handled)
+	assert.NoError(t, otherErr)
+}
+
+func TestRecoveryRequiresInProgressSuffix(t *testing.T) {
+	_, err := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000, WithRecovery(nil),
+	)
+
+	assert.Error(t, err)
+}
+
+func TestTruncateAfterLast(t *testing.T) {
+	fn := newFileName(t)
+	_ = ioutil.WriteFile(fn, []byte("a\nb\npart"), 0644)
+
+	err := TruncateAfterLast('\n')(fn)
+	content, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a\nb\n", string(content))
+}
+
+func TestTruncateAfterLastAcrossChunks(t *testing.T) {
+	fn := newFileName(t)
+	_ = ioutil.WriteFile(fn, append([]byte("a\n"), make([]byte, 100*1024)...), 0644)
+
+	err := TruncateAfterLast('\n')(fn)
+	content, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a\n", string(content))
+}
@@ -0,0 +1,186 @@
+package gofile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrTruncated is returned by FramedReader when the file ends in the
+	// middle of a record.
+	ErrTruncated = errors.New("truncated record")
+
+	// ErrCorrupt is returned by FramedReader when a record does not match its
+	// checksum or has an invalid length.
+	ErrCorrupt = errors.New("corrupt record")
+)
+
+// DefaultMaxRecordSize bounds the records read by a FramedReader, larger
+// lengths are considered corrupt.
+const DefaultMaxRecordSize = 64 << 20
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// FramedWriter writes records as a uvarint length, the payload and the
+// little endian CRC32C of the payload, one Write per record.
+type FramedWriter struct {
+	m contracts.FileManager
+}
+
+func NewFramedWriter(m contracts.FileManager) *FramedWriter {
+	return &FramedWriter{m: m}
+}
+
+// WriteRecord frames and writes payload, it returns the size of the frame.
+func (w *FramedWriter) WriteRecord(payload []byte) (int, error) {
+	frame := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(payload)+4)
+	n := binary.PutUvarint(frame, uint64(len(payload)))
+	frame = append(frame[:n], payload...)
+
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc32.Checksum(payload, castagnoli))
+	frame = append(frame, sum[:]...)
+
+	return w.m.Write(frame)
+}
+
+// FramedReader iterates the records written by a FramedWriter:
+//
+//	for r.Next() {
+//		process(r.Record())
+//	}
+//	if err := r.Err(); err != nil { ... }
+type FramedReader struct {
+	r      *bufio.Reader
+	record []byte
+	offset int64
+	err    error
+	// MaxRecordSize defaults to DefaultMaxRecordSize.
+	MaxRecordSize int
+}
+
+func NewFramedReader(r io.Reader) *FramedReader {
+	return &FramedReader{
+		r:             bufio.NewReader(r),
+		MaxRecordSize: DefaultMaxRecordSize,
+	}
+}
+
+// Next reads the next record, it returns false at the end of the records or
+// on error.
+func (r *FramedReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	record, n, err := r.read()
+	if err != nil {
+		r.record = nil
+		if err != io.EOF {
+			r.err = errors.Wrapf(err, "at offset %d", r.offset)
+		}
+
+		return false
+	}
+
+	r.record = record
+	r.offset += n
+
+	return true
+}
+
+// Record returns the record read by the last call to Next.
+func (r *FramedReader) Record() []byte {
+	return r.record
+}
+
+// Offset returns the offset following the last valid record.
+func (r *FramedReader) Offset() int64 {
+	return r.offset
+}
+
+// Err returns the error that stopped Next, ErrTruncated and ErrCorrupt are
+// wrapped with the offset of the faulty record.
+func (r *FramedReader) Err() error {
+	return r.err
+}
+
+func (r *FramedReader) read() ([]byte, int64, error) {
+	cr := &countingByteReader{r: r.r}
+
+	length, err := binary.ReadUvarint(cr)
+	if err == io.EOF && cr.n == 0 {
+		return nil, 0, io.EOF
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, 0, ErrTruncated
+	}
+
+	if err != nil || length > uint64(r.MaxRecordSize) {
+		return nil, 0, ErrCorrupt
+	}
+
+	buf := make([]byte, int(length)+4)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, 0, ErrTruncated
+		}
+
+		return nil, 0, err
+	}
+
+	payload := buf[:length]
+	if crc32.Checksum(payload, castagnoli) != binary.LittleEndian.Uint32(buf[length:]) {
+		return nil, 0, ErrCorrupt
+	}
+
+	return payload, cr.n + int64(len(buf)), nil
+}
+
+type countingByteReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (c *countingByteReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+
+	return b, err
+}
+
+// TruncateFramed is a Repairer truncating a framed file after its last valid
+// record, dropping a torn or corrupt tail. Read errors leave the file as is.
+func TruncateFramed() Repairer {
+	return func(path string) error {
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return errors.Wrap(err, "unable to open file")
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return errors.Wrap(err, "unable to stat file")
+		}
+
+		r := NewFramedReader(f)
+		for r.Next() {
+		}
+
+		if err := r.Err(); err != nil && !errors.Is(err, ErrTruncated) && !errors.Is(err, ErrCorrupt) {
+			return err
+		}
+
+		return truncate(f, r.Offset(), info.Size())
+	}
+}
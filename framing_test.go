@@ -0,0 +1,128 @@
+package gofile
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFramedRecords(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn)
+	w := NewFramedWriter(m)
+
+	n, err := w.WriteRecord([]byte("hello"))
+	_, _ = w.WriteRecord([]byte{})
+	_, _ = w.WriteRecord(bytes.Repeat([]byte("a"), 300))
+	m.Close()
+
+	f, _ := os.Open(fn)
+	defer f.Close()
+	r := NewFramedReader(f)
+
+	var records [][]byte
+	for r.Next() {
+		records = append(records, r.Record())
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.NoError(t, r.Err())
+	assert.Equal(t, [][]byte{[]byte("hello"), {}, bytes.Repeat([]byte("a"), 300)}, records)
+	assert.Equal(t, int64(10+5+306), r.Offset())
+}
+
+func TestFramedReaderDetectsTruncatedRecords(t *testing.T) {
+	frames := framedRecords("hello", "world")
+
+	for _, cut := range []int{len(frames) - 1, len(frames) - 5, 11} {
+		r := NewFramedReader(bytes.NewReader(frames[:cut]))
+
+		assert.True(t, r.Next())
+		assert.False(t, r.Next())
+		assert.True(t, errors.Is(r.Err(), ErrTruncated), "cut at %d", cut)
+		assert.Equal(t, int64(10), r.Offset())
+	}
+}
+
+func TestFramedReaderDetectsCorruptRecords(t *testing.T) {
+	frames := framedRecords("hello", "world")
+	frames[len(frames)-6] = 'W'
+
+	r := NewFramedReader(bytes.NewReader(frames))
+
+	assert.True(t, r.Next())
+	assert.False(t, r.Next())
+	assert.True(t, errors.Is(r.Err(), ErrCorrupt))
+}
+
+func TestFramedReaderRejectsHugeLengths(t *testing.T) {
+	r := NewFramedReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x0f}))
+
+	assert.False(t, r.Next())
+	assert.True(t, errors.Is(r.Err(), ErrCorrupt))
+}
+
+func TestTruncateFramed(t *testing.T) {
+	fn := newFileName(t)
+	frames := framedRecords("hello", "world")
+	_ = ioutil.WriteFile(fn, frames[:len(frames)-2], 0644)
+
+	err := TruncateFramed()(fn)
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, frames[:10], c)
+}
+
+func TestTruncateFramedDropsCorruptTail(t *testing.T) {
+	fn := newFileName(t)
+	frames := framedRecords("hello", "world")
+	frames[len(frames)-1] ^= 0xff
+	_ = ioutil.WriteFile(fn, frames, 0644)
+
+	err := TruncateFramed()(fn)
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, frames[:10], c)
+}
+
+func framedRecords(records ...string) []byte {
+	buf := &bytes.Buffer{}
+	w := NewFramedWriter(&bufferManager{buf: buf})
+
+	for _, r := range records {
+		_, _ = w.WriteRecord([]byte(r))
+	}
+
+	return buf.Bytes()
+}
+
+type bufferManager struct {
+	buf *bytes.Buffer
+}
+
+func (m *bufferManager) Write(b []byte) (int, error) {
+	return m.buf.Write(b)
+}
+
+func (m *bufferManager) WrittenBytes() uint64 {
+	return uint64(m.buf.Len())
+}
+
+func (m *bufferManager) Flush() error {
+	return nil
+}
+
+func (m *bufferManager) Sync() error {
+	return nil
+}
+
+func (m *bufferManager) Close() error {
+	return nil
+}
@@ -3,7 +3,9 @@ module github.com/paulhenri-l/gofile
 go 1.15
 
 require (
+	github.com/cespare/xxhash/v2 v2.1.2
 	github.com/golang/mock v1.4.4
+	github.com/klauspost/compress v1.13.6
 	github.com/pkg/errors v0.9.1
 	github.com/rs/xid v1.2.1
 	github.com/stretchr/testify v1.7.0
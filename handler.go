@@ -0,0 +1,220 @@
+package gofile
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+)
+
+// RotationEventHandler is implemented by the contracts.RotatedFileHandler
+// wanting the RotatedFile rather than its path, HandleRotation is then called
+// instead of Handle.
+type RotationEventHandler interface {
+	HandleRotation(f RotatedFile) error
+}
+
+// Handle calls f with a RotatedFile only holding path, it makes
+// RotatedFileHandler a contracts.RotatedFileHandler that never fails.
+func (f RotatedFileHandler) Handle(path string) error {
+	f(RotatedFile{Path: path})
+
+	return nil
+}
+
+// HandleRotation calls f, it makes RotatedFileHandler a RotationEventHandler.
+func (f RotatedFileHandler) HandleRotation(file RotatedFile) error {
+	f(file)
+
+	return nil
+}
+
+// DeadLetterHandler receives the rotated files whose handler still failed
+// after every retry, along with the last error.
+type DeadLetterHandler func(f RotatedFile, err error)
+
+// HandlerRetry configures how failing contracts.RotatedFileHandler calls are
+// retried.
+type HandlerRetry struct {
+	// Attempts is the maximum number of calls per file, retries are disabled
+	// below 2.
+	Attempts int
+	// Backoff spaces the attempts, DefaultBackoff is used when zero.
+	Backoff Backoff
+}
+
+// WithHandlerRetry retries the handler when it returns an error. Without
+// WithHandlerWorkers the retries delay the writes triggering rotations.
+func WithHandlerRetry(r HandlerRetry) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		if r.Backoff == (Backoff{}) {
+			r.Backoff = DefaultBackoff
+		}
+
+		rm.handlerRetry = r
+	}
+}
+
+// WithDeadLetterHandler sets the handler receiving the files whose handler
+// exhausted its retries. Without it they are reported to the ErrorHandler.
+func WithDeadLetterHandler(h DeadLetterHandler) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.deadLetterHandler = h
+	}
+}
+
+// WithHandlerWorkers calls the rotated file handlers from a pool of workers
+// instead of the goroutine rotating the file, so that slow handlers do not
+// block writes. The writes rotating files wait while queueSize files wait for a
+// worker.
+func WithHandlerWorkers(workers, queueSize int) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		if workers <= 0 {
+			workers = 1
+		}
+
+		if queueSize < 0 {
+			queueSize = 0
+		}
+
+		rm.handlerWorkers = workers
+		rm.handlerQueueSize = queueSize
+	}
+}
+
+// startHandlers starts the handler workers, if any.
+func (rm *RotatingManager) startHandlers() {
+	if rm.handlerWorkers == 0 {
+		return
+	}
+
+	rm.handlerQueue = make(chan RotatedFile, rm.handlerQueueSize)
+	rm.handlers = &sync.WaitGroup{}
+
+	for i := 0; i < rm.handlerWorkers; i++ {
+		rm.handlers.Add(1)
+
+		go func() {
+			defer rm.handlers.Done()
+
+			for f := range rm.handlerQueue {
+				rm.handle(f)
+			}
+		}()
+	}
+}
+
+// stopHandlers waits for the queued files to be handled or for ctx to be done.
+func (rm *RotatingManager) stopHandlers(ctx context.Context) error {
+	if rm.handlerQueue == nil {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		close(rm.handlerQueue)
+		rm.handlers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "rotated file handlers did not drain")
+	}
+}
+
+// rotatedFileHandler returns the current handler, it may be replaced while
+// the workers run.
+func (rm *RotatingManager) rotatedFileHandler() contracts.RotatedFileHandler {
+	rm.handlerMtx.Lock()
+	defer rm.handlerMtx.Unlock()
+
+	return rm.fileHandler
+}
+
+func (rm *RotatingManager) notifyRotationHandler(f RotatedFile) {
+	rm.handlerMtx.Lock()
+	h := rm.fileHandler
+
+	// Journaled files wait for a handler, which is often set after the
+	// journal was replayed.
+	if h == nil && rm.journal != nil {
+		rm.unclaimed = append(rm.unclaimed, f)
+		rm.handlerMtx.Unlock()
+		return
+	}
+	rm.handlerMtx.Unlock()
+
+	if h == nil {
+		rm.handled(f.Path)
+		return
+	}
+
+	if rm.handlerQueue != nil {
+		rm.handlerQueue <- f
+		return
+	}
+
+	rm.handle(f)
+}
+
+func (rm *RotatingManager) handle(f RotatedFile) {
+	err := rm.handleWithRetry(f)
+	if err == nil {
+		rm.handled(f.Path)
+		return
+	}
+
+	if rm.deadLetterHandler != nil {
+		rm.deadLetterHandler(f, err)
+		rm.handled(f.Path)
+		return
+	}
+
+	// Failed files stay in the journal and are handed over again on restart.
+	rm.reportError(err)
+}
+
+// handled releases a file the handler is done with, the retention may then
+// prune it.
+func (rm *RotatingManager) handled(path string) {
+	rm.journalDone(path)
+	rm.unhandled.remove(path)
+}
+
+func (rm *RotatingManager) handleWithRetry(f RotatedFile) error {
+	var err error
+	fh := rm.rotatedFileHandler()
+	if fh == nil {
+		// The handler was removed while the file was queued.
+		return nil
+	}
+
+	handle := func() error {
+		return fh.Handle(f.Path)
+	}
+
+	if h, ok := fh.(RotationEventHandler); ok {
+		handle = func() error {
+			return h.HandleRotation(f)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err = handle(); err == nil {
+			return nil
+		}
+
+		if attempt+1 >= rm.handlerRetry.Attempts {
+			break
+		}
+
+		time.Sleep(rm.handlerRetry.Backoff.Duration(attempt))
+	}
+
+	return errors.Wrapf(err, "rotated file handler failed for %s", f.Path)
+}
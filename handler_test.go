@@ -0,0 +1,235 @@
+package gofile
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/paulhenri-l/gofile/contracts"
+	m "github.com/paulhenri-l/gofile/mocks/contracts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatedFileHandlerIsAFileHandler(t *testing.T) {
+	var received string
+	var h contracts.RotatedFileHandler = RotatedFileHandler(func(f RotatedFile) {
+		received = f.Path
+	})
+
+	err := h.Handle("some/path")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "some/path", received)
+}
+
+func TestFileHandlerCalledOnRotation(t *testing.T) {
+	h := newTestFileHandler(t)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHandler(h),
+	)
+	path := rm.m.path
+
+	h.EXPECT().Handle(path).Return(nil)
+
+	_, _ = rm.Write([]byte("hello"))
+}
+
+func TestFileHandlerIsRetried(t *testing.T) {
+	h := newTestFileHandler(t)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHandler(h),
+		WithHandlerRetry(HandlerRetry{Attempts: 3, Backoff: Backoff{Initial: time.Millisecond}}),
+	)
+	path := rm.m.path
+
+	gomock.InOrder(
+		h.EXPECT().Handle(path).Return(errors.New("I am broken")),
+		h.EXPECT().Handle(path).Return(nil),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+}
+
+func TestFileHandlerDeadLetter(t *testing.T) {
+	var dead RotatedFile
+	var deadErr error
+	h := newTestFileHandler(t)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHandler(h),
+		WithHandlerRetry(HandlerRetry{Attempts: 3, Backoff: Backoff{Initial: time.Millisecond}}),
+		WithDeadLetterHandler(func(f RotatedFile, err error) {
+			dead, deadErr = f, err
+		}),
+	)
+	path := rm.m.path
+
+	h.EXPECT().Handle(path).Return(errors.New("I am broken")).Times(3)
+
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.Equal(t, path, dead.Path)
+	assert.Equal(t, ReasonSize, dead.Reason)
+	assert.Equal(t, uint64(5), dead.WrittenBytes)
+	assert.Error(t, deadErr)
+}
+
+func TestFileHandlerErrorReportedWithoutDeadLetter(t *testing.T) {
+	var reported error
+	h := newTestFileHandler(t)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHandler(h),
+		WithErrorHandler(func(err error) {
+			reported = err
+		}),
+	)
+	path := rm.m.path
+
+	h.EXPECT().Handle(path).Return(errors.New("I am broken"))
+
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.Error(t, reported)
+}
+
+func TestHandlerWorkersDoNotBlockWrites(t *testing.T) {
+	release := make(chan struct{})
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithHandlerWorkers(1, 2),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			<-release
+			handled <- f.Path
+		}),
+	)
+
+	_, err1 := rm.Write([]byte("hello"))
+	_, err2 := rm.Write([]byte("world"))
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Len(t, handled, 0)
+
+	close(release)
+	rm.Close()
+
+	assert.Len(t, handled, 3)
+}
+
+func TestHandlerWorkersQueueIsBounded(t *testing.T) {
+	release := make(chan struct{})
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithHandlerWorkers(1, 1),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			<-release
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("world"))
+
+	written := make(chan bool)
+	go func() {
+		_, _ = rm.Write([]byte("again"))
+		written <- true
+	}()
+
+	select {
+	case <-written:
+		t.Fatal("write did not wait for the full queue")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	// The waiting write does not hold the lock.
+	assert.False(t, rm.Degraded())
+
+	close(release)
+	<-written
+	rm.Close()
+}
+
+func TestHandlerCanBeReplacedWhileWorkersRun(t *testing.T) {
+	var handled int32
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithHandlerWorkers(2, 10),
+	)
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 20; i++ {
+			_, _ = rm.Write([]byte("hello"))
+		}
+
+		done <- true
+	}()
+
+	for i := 0; i < 20; i++ {
+		rm.WithRotatedFileHandler(func(f RotatedFile) {
+			atomic.AddInt32(&handled, 1)
+		})
+	}
+
+	<-done
+	rm.Close()
+
+	assert.NotZero(t, atomic.LoadInt32(&handled))
+}
+
+func TestCloseContextWaitsForHandlers(t *testing.T) {
+	var handled int32
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithHandlerWorkers(2, 10),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			time.Sleep(time.Millisecond * 10)
+			atomic.AddInt32(&handled, 1)
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		_, _ = rm.Write([]byte("hello"))
+	}
+
+	err := rm.CloseContext(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), atomic.LoadInt32(&handled))
+}
+
+func TestCloseContextTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 5,
+		WithHandlerWorkers(1, 10),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			<-release
+		}),
+	)
+	_, _ = rm.Write([]byte("hello"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	err := rm.CloseContext(ctx)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func newTestFileHandler(t *testing.T) *m.MockRotatedFileHandler {
+	ctl := gomock.NewController(t)
+	t.Cleanup(func() {
+		ctl.Finish()
+	})
+
+	return m.NewMockRotatedFileHandler(ctl)
+}
@@ -0,0 +1,143 @@
+package gofile
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// FileHooks run around the life of every file opened by a RotatingManager or
+// a Manager.
+type FileHooks struct {
+	// OnOpen writes at the start of every new file, before any record. Files
+	// holding nothing more are still considered empty.
+	OnOpen func(w io.Writer) error
+	// OnClose writes at the end of every file, right before it is closed.
+	OnClose func(w io.Writer, stats FileStats) error
+	// CountBytes includes the bytes written by the hooks in WrittenBytes, and
+	// thus in size based rotations. They are excluded by default.
+	CountBytes bool
+}
+
+// WithFileHooks adds hooks run for every file, hooks run in the order they
+// were added.
+func WithFileHooks(h FileHooks) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.fileHooks = append(rm.fileHooks, h)
+	}
+}
+
+// WithManagerHooks adds hooks run when the Manager is created and closed.
+func WithManagerHooks(h FileHooks) ManagerOption {
+	return func(m *Manager) {
+		m.hooks = append(m.hooks, h)
+	}
+}
+
+// hookWriter counts the bytes written by a hook.
+type hookWriter struct {
+	w io.Writer
+	n uint64
+}
+
+func (h *hookWriter) Write(b []byte) (int, error) {
+	n, err := h.w.Write(b)
+	h.n += uint64(n)
+
+	return n, err
+}
+
+// runOpenHooks runs the OnOpen hooks and returns the bytes they wrote that
+// must be counted and those that must not.
+func runOpenHooks(hooks []FileHooks, w io.Writer) (uint64, uint64, error) {
+	var counted, uncounted uint64
+
+	for _, h := range hooks {
+		if h.OnOpen == nil {
+			continue
+		}
+
+		hw := &hookWriter{w: w}
+		err := h.OnOpen(hw)
+
+		if h.CountBytes {
+			counted += hw.n
+		} else {
+			uncounted += hw.n
+		}
+
+		if err != nil {
+			return counted, uncounted, errors.Wrap(err, "open hook failed")
+		}
+	}
+
+	return counted, uncounted, nil
+}
+
+// runCloseHooks runs the OnClose hooks like runOpenHooks, stats is only
+// computed when there is at least one of them.
+func runCloseHooks(hooks []FileHooks, w io.Writer, stats func() FileStats) (uint64, uint64, error) {
+	var counted, uncounted uint64
+	var s *FileStats
+
+	for _, h := range hooks {
+		if h.OnClose == nil {
+			continue
+		}
+
+		if s == nil {
+			v := stats()
+			s = &v
+		}
+
+		hw := &hookWriter{w: w}
+		err := h.OnClose(hw, *s)
+
+		if h.CountBytes {
+			counted += hw.n
+		} else {
+			uncounted += hw.n
+		}
+
+		if err != nil {
+			return counted, uncounted, errors.Wrap(err, "close hook failed")
+		}
+	}
+
+	return counted, uncounted, nil
+}
+
+// openHooks runs the OnOpen hooks of a new file, the counted bytes add up to
+// WrittenBytes.
+func (rm *RotatingManager) openHooks(dm *decoratedManager) error {
+	counted, uncounted, err := runOpenHooks(rm.fileHooks, dm.FileManager)
+	rm.writtenBytes += counted
+	dm.hookBytes += uncounted
+
+	return err
+}
+
+// closeManager runs the OnClose hooks then closes dm. Hook failures are
+// reported and do not prevent the file from being closed.
+func (rm *RotatingManager) closeManager(dm *decoratedManager) error {
+	stats := func() FileStats {
+		s := dm.stats()
+		if c, ok := dm.FileManager.(checksummer); ok {
+			s.Checksum = c.Checksum()
+		}
+
+		return s
+	}
+
+	counted, uncounted, err := runCloseHooks(rm.fileHooks, dm.FileManager, stats)
+	rm.writtenBytes += counted
+	dm.hookBytes += uncounted
+
+	if err != nil {
+		rm.reportError(&RotationError{Op: "hook", Path: dm.path, Err: err})
+	}
+
+	dm.syncs.Wait()
+
+	return dm.Close()
+}
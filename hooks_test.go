@@ -0,0 +1,244 @@
+package gofile
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenHooksRunForEveryFile(t *testing.T) {
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHooks(FileHooks{OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("v1|"))
+			return err
+		}}),
+		WithFileHooks(FileHooks{OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("v2|"))
+			return err
+		}}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("world"))
+	c1, _ := ioutil.ReadFile(<-handled)
+	c2, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "v1|v2|hello", string(c1))
+	assert.Equal(t, "v1|v2|world", string(c2))
+
+	rm.Close()
+}
+
+func TestBrokenOpenHook(t *testing.T) {
+	dir := t.TempDir()
+	rm, err := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithFileHooks(FileHooks{OnOpen: func(w io.Writer) error {
+			return errors.New("I am broken")
+		}}),
+	)
+	files, _ := ioutil.ReadDir(dir)
+
+	assert.Nil(t, rm)
+	assert.Error(t, err)
+	assert.Len(t, files, 0)
+}
+
+func TestFilesWithOnlyHooksAreEmpty(t *testing.T) {
+	var handled []string
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", AgePolicy(time.Millisecond*5), nil,
+		WithFileHooks(FileHooks{OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("header"))
+			return err
+		}}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = append(handled, f.Path)
+		}),
+	)
+	m1 := rm.m
+
+	time.Sleep(time.Millisecond * 20)
+
+	rm.mtx.Lock()
+	m2 := rm.m
+	rm.mtx.Unlock()
+
+	assert.Equal(t, m1, m2)
+	assert.Len(t, handled, 0)
+
+	rm.Close()
+}
+
+func TestCloseHooksRunForEveryFile(t *testing.T) {
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(2), nil,
+		WithFileHooks(FileHooks{OnClose: func(w io.Writer, stats FileStats) error {
+			_, err := fmt.Fprintf(w, "|%d records, %d bytes", stats.Writes, stats.WrittenBytes)
+			return err
+		}}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("world"))
+	_, _ = rm.Write([]byte("again"))
+	rm.Close()
+
+	c1, _ := ioutil.ReadFile(<-handled)
+	c2, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "helloworld|2 records, 10 bytes", string(c1))
+	assert.Equal(t, "again|1 records, 5 bytes", string(c2))
+}
+
+func TestCloseHooksReceiveChecksum(t *testing.T) {
+	var stats FileStats
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithManagerOptions(WithChecksum(SHA256)),
+		WithFileHooks(FileHooks{OnClose: func(w io.Writer, s FileStats) error {
+			stats = s
+			return nil
+		}}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+	want := sha256.Sum256([]byte("hello"))
+
+	assert.Equal(t, "sha256", stats.Checksum.Algorithm)
+	assert.Equal(t, want[:], stats.Checksum.Sum)
+}
+
+func TestHookBytesAreNotCountedByDefault(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(5), nil,
+		WithFileHooks(FileHooks{OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("header"))
+			return err
+		}}),
+	)
+	defer rm.Close()
+	m1 := rm.m
+
+	_, _ = rm.Write([]byte("hi"))
+
+	rm.mtx.Lock()
+	stats := rm.m.stats()
+	rm.mtx.Unlock()
+
+	assert.Equal(t, m1, rm.m)
+	assert.Equal(t, uint64(2), stats.WrittenBytes)
+	assert.Equal(t, uint64(8), stats.DiskBytes)
+}
+
+func TestHookBytesCanBeCounted(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(5), nil,
+		WithFileHooks(FileHooks{CountBytes: true, OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("header"))
+			return err
+		}}),
+	)
+	defer rm.Close()
+	m1 := rm.m
+
+	_, _ = rm.Write([]byte("hi"))
+
+	assert.NotEqual(t, m1, rm.m)
+	assert.Equal(t, uint64(6+2+6), rm.WrittenBytes())
+}
+
+func TestBrokenCloseHook(t *testing.T) {
+	var reported error
+	var handled string
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithFileHooks(FileHooks{OnClose: func(w io.Writer, stats FileStats) error {
+			return errors.New("I am broken")
+		}}),
+		WithErrorHandler(func(err error) {
+			reported = err
+		}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = f.Path
+		}),
+	)
+	path := rm.m.path
+
+	_, _ = rm.Write([]byte("hello"))
+	rerr := &RotationError{}
+
+	assert.True(t, errors.As(reported, &rerr))
+	assert.Equal(t, "hook", rerr.Op)
+	assert.Equal(t, path, handled)
+}
+
+func TestManagerHooks(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn, WithManagerHooks(FileHooks{
+		OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("header|"))
+			return err
+		},
+		OnClose: func(w io.Writer, stats FileStats) error {
+			_, err := fmt.Fprintf(w, "|%d", stats.Writes)
+			return err
+		},
+	}))
+
+	_, _ = m.Write([]byte("hello"))
+	_, _ = m.Write([]byte("world"))
+	written := m.WrittenBytes()
+	err := m.Close()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), written)
+	assert.Equal(t, "header|helloworld|2", string(c))
+	assert.NoError(t, m.Close())
+}
+
+func TestManagerHookBytesCanBeCounted(t *testing.T) {
+	m, _ := NewManager(newFileName(t), WithManagerHooks(FileHooks{
+		CountBytes: true,
+		OnOpen: func(w io.Writer) error {
+			_, err := w.Write([]byte("header|"))
+			return err
+		},
+	}))
+
+	_, _ = m.Write([]byte("hello"))
+
+	assert.Equal(t, uint64(12), m.WrittenBytes())
+}
+
+func TestBrokenManagerHooks(t *testing.T) {
+	fn := newFileName(t)
+	m, err := NewManager(fn, WithManagerHooks(FileHooks{
+		OnOpen: func(w io.Writer) error {
+			return errors.New("I am broken")
+		},
+	}))
+	_, serr := os.Stat(fn)
+
+	assert.Nil(t, m)
+	assert.Error(t, err)
+	assert.True(t, os.IsNotExist(serr))
+}
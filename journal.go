@@ -0,0 +1,281 @@
+package gofile
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// WithJournal records the rotated files in the named journal, inside the
+// RotatingManager directory, until their handler succeeded. Files left
+// unhandled by a crash are handed over again at construction, handlers are
+// thus called at least once per file and must be idempotent. Files stay
+// pending while no handler is set and are handed to the first one set.
+func WithJournal(name string) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.journalPath = filepath.Join(rm.path, name)
+	}
+}
+
+// journal is an append only log of "+path" lines for files handed over and
+// "-path" lines for files handled.
+type journal struct {
+	mtx     *sync.Mutex
+	path    string
+	file    *os.File
+	pending map[string]bool
+	closed  bool
+}
+
+// openJournal opens the journal at path and returns the files still pending,
+// in the order they were handed over.
+func openJournal(path string) (*journal, []string, error) {
+	pending, err := readJournal(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	j := &journal{
+		mtx:     &sync.Mutex{},
+		path:    path,
+		pending: make(map[string]bool, len(pending)),
+	}
+
+	for _, p := range pending {
+		j.pending[p] = true
+	}
+
+	if err := j.compact(pending); err != nil {
+		return nil, nil, err
+	}
+
+	return j, pending, nil
+}
+
+func readJournal(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open journal")
+	}
+	defer f.Close()
+
+	var order []string
+	pending := map[string]bool{}
+	s := bufio.NewScanner(f)
+
+	for s.Scan() {
+		line := s.Text()
+		if len(line) < 2 {
+			continue
+		}
+
+		switch p := line[1:]; line[0] {
+		case '+':
+			if !pending[p] {
+				order = append(order, p)
+			}
+
+			pending[p] = true
+		case '-':
+			delete(pending, p)
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, errors.Wrap(err, "unable to read journal")
+	}
+
+	var result []string
+	for _, p := range order {
+		if pending[p] {
+			result = append(result, p)
+			delete(pending, p)
+		}
+	}
+
+	return result, nil
+}
+
+// compact rewrites the journal with only the pending files and opens it for
+// appending.
+func (j *journal) compact(pending []string) error {
+	tmp := j.path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return errors.Wrap(err, "unable to create journal")
+	}
+
+	w := bufio.NewWriter(f)
+	for _, p := range pending {
+		_, _ = w.WriteString("+" + p + "\n")
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to write journal")
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to sync journal")
+	}
+
+	if err := os.Rename(tmp, j.path); err != nil {
+		f.Close()
+		return errors.Wrap(err, "unable to rename journal")
+	}
+
+	syncDir(filepath.Dir(j.path))
+	j.file = f
+
+	return nil
+}
+
+// add records a file handed over.
+func (j *journal) add(path string) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.closed || j.pending[path] {
+		return nil
+	}
+
+	j.pending[path] = true
+
+	return j.append("+" + path)
+}
+
+// done records a file handled, the journal is truncated once nothing is
+// pending anymore.
+func (j *journal) done(path string) error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.closed || !j.pending[path] {
+		return nil
+	}
+
+	delete(j.pending, path)
+
+	if len(j.pending) == 0 {
+		return j.truncate()
+	}
+
+	return j.append("-" + path)
+}
+
+func (j *journal) append(line string) error {
+	if strings.ContainsAny(line, "\r\n") {
+		return errors.Errorf("unable to journal %q", line[1:])
+	}
+
+	if _, err := j.file.WriteString(line + "\n"); err != nil {
+		return errors.Wrap(err, "unable to write journal")
+	}
+
+	return errors.Wrap(j.file.Sync(), "unable to sync journal")
+}
+
+func (j *journal) truncate() error {
+	if err := j.file.Truncate(0); err != nil {
+		return errors.Wrap(err, "unable to truncate journal")
+	}
+
+	if _, err := j.file.Seek(0, 0); err != nil {
+		return errors.Wrap(err, "unable to truncate journal")
+	}
+
+	return errors.Wrap(j.file.Sync(), "unable to sync journal")
+}
+
+func (j *journal) close() error {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if j.closed {
+		return nil
+	}
+
+	j.closed = true
+
+	return errors.Wrap(j.file.Close(), "unable to close journal")
+}
+
+// openJournal opens the journal, if any, and hands the files left pending
+// by a previous run over again. Files compressed before the crash are handed
+// over compressed.
+func (rm *RotatingManager) openJournal() error {
+	if rm.journalPath == "" {
+		return nil
+	}
+
+	j, pending, err := openJournal(rm.journalPath)
+	if err != nil {
+		return err
+	}
+
+	rm.journal = j
+
+	for _, path := range pending {
+		if _, err := os.Stat(path); err != nil && rm.compression != nil {
+			compressed := path + rm.compression.Codec.Extension()
+
+			if _, err := os.Stat(compressed); err == nil {
+				rm.journalAdd(compressed)
+				rm.journalDone(path)
+				path = compressed
+			}
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			rm.journalDone(path)
+			continue
+		}
+
+		rm.unhandled.add(path)
+		rm.dispatch(recoveredFile(path))
+	}
+
+	return nil
+}
+
+func (rm *RotatingManager) journalAdd(path string) {
+	if rm.journal == nil {
+		return
+	}
+
+	if err := rm.journal.add(path); err != nil {
+		rm.reportError(err)
+	}
+}
+
+func (rm *RotatingManager) journalDone(path string) {
+	if rm.journal == nil {
+		return
+	}
+
+	if err := rm.journal.done(path); err != nil {
+		rm.reportError(err)
+	}
+}
+
+func (rm *RotatingManager) closeJournal() error {
+	if rm.journal == nil {
+		return nil
+	}
+
+	return rm.journal.close()
+}
+
+func (rm *RotatingManager) isJournal(path string) bool {
+	return rm.journalPath != "" && (path == rm.journalPath || path == rm.journalPath+".tmp")
+}
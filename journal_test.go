@@ -0,0 +1,176 @@
+package gofile
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	_ = ioutil.WriteFile(path, []byte("+a\n+b\n-a\n+c\n+b\n"), 0644)
+
+	pending, err := readJournal(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, pending)
+}
+
+func TestJournalIsCompactedOnOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	_ = ioutil.WriteFile(path, []byte("+a\n+b\n-a\n"), 0644)
+
+	j, _, _ := openJournal(path)
+	j.close()
+	c, _ := ioutil.ReadFile(path)
+
+	assert.Equal(t, "+b\n", string(c))
+}
+
+func TestJournalIsTruncatedWhenNothingPending(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+	j, _, _ := openJournal(path)
+
+	_ = j.add("a")
+	_ = j.add("b")
+	_ = j.done("a")
+	c1, _ := ioutil.ReadFile(path)
+	_ = j.done("b")
+	c2, _ := ioutil.ReadFile(path)
+	j.close()
+
+	assert.Equal(t, "+a\n+b\n-a\n", string(c1))
+	assert.Len(t, c2, 0)
+}
+
+func TestJournalReplaysUnhandledFiles(t *testing.T) {
+	dir := t.TempDir()
+	rotated := filepath.Join(dir, "events_1")
+	_ = ioutil.WriteFile(rotated, []byte("hello"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "journal"), []byte("+"+rotated+"\n+"+filepath.Join(dir, "gone")+"\n"), 0644)
+
+	var handled []string
+	rm, _ := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = append(handled, f.Path)
+		}),
+	)
+
+	assert.Equal(t, []string{rotated}, handled)
+
+	rm.Close()
+	pending, _ := readJournal(filepath.Join(dir, "journal"))
+
+	assert.Len(t, pending, 0)
+}
+
+func TestJournalReplaysToHandlerSetLater(t *testing.T) {
+	dir := t.TempDir()
+	rotated := filepath.Join(dir, "events_1")
+	_ = ioutil.WriteFile(rotated, []byte("hello"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "journal"), []byte("+"+rotated+"\n"), 0644)
+
+	var handled []string
+	rm, _ := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+	)
+	pending, _ := readJournal(filepath.Join(dir, "journal"))
+
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		handled = append(handled, f.Path)
+	})
+
+	assert.Equal(t, []string{rotated}, pending)
+	assert.Equal(t, []string{rotated}, handled)
+
+	rm.Close()
+}
+
+func TestJournalKeepsFilesWithoutHandler(t *testing.T) {
+	dir := t.TempDir()
+	rotated := filepath.Join(dir, "events_1")
+	_ = ioutil.WriteFile(rotated, []byte("hello"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "journal"), []byte("+"+rotated+"\n"), 0644)
+
+	rm, _ := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+	)
+	rm.Close()
+	pending, _ := readJournal(filepath.Join(dir, "journal"))
+
+	assert.Contains(t, pending, rotated)
+}
+
+func TestJournalReplaysCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	rotated := filepath.Join(dir, "events_1")
+	_ = ioutil.WriteFile(rotated+".gz", []byte("compressed"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "journal"), []byte("+"+rotated+"\n"), 0644)
+
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+		WithCompression(Compression{Codec: Gzip}),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	assert.Equal(t, rotated+".gz", <-handled)
+
+	rm.Close()
+}
+
+func TestJournalKeepsFailedFiles(t *testing.T) {
+	dir := t.TempDir()
+	broken := newTestFileHandler(t)
+	rm, _ := NewRotatingManagerWithPolicy(
+		dir, "events_", CountPolicy(1), nil,
+		WithJournal("journal"),
+		WithFileHandler(broken),
+	)
+	path := rm.m.path
+	broken.EXPECT().Handle(path).Return(errors.New("I am broken"))
+	broken.EXPECT().Handle(gomock.Any()).Return(nil)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	var handled []string
+	rm, _ = NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = append(handled, f.Path)
+		}),
+	)
+
+	assert.Equal(t, []string{path}, handled)
+
+	rm.Close()
+}
+
+func TestJournalIsNotARotatedFile(t *testing.T) {
+	dir := t.TempDir()
+	rm, _ := NewRotatingManager(
+		dir, "", time.Second*100, 1000,
+		WithJournal("journal"),
+		WithRetention(Retention{MaxFiles: 1}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+	files, _ := ioutil.ReadDir(dir)
+
+	assert.Len(t, files, 2)
+}
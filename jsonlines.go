@@ -0,0 +1,59 @@
+package gofile
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+)
+
+// maxPooledEncoderSize keeps the buffers of unusually large values out of the
+// pool.
+const maxPooledEncoderSize = 64 * 1024
+
+type lineEncoder struct {
+	buf *bytes.Buffer
+	enc *json.Encoder
+}
+
+var lineEncoders = sync.Pool{
+	New: func() interface{} {
+		buf := &bytes.Buffer{}
+
+		return &lineEncoder{buf: buf, enc: json.NewEncoder(buf)}
+	},
+}
+
+// JSONLinesWriter writes values as JSON Lines, one JSON document per line and
+// one Write per line. JSONLinesWriter is threadsafe when its manager is.
+type JSONLinesWriter struct {
+	m contracts.FileManager
+}
+
+func NewJSONLinesWriter(m contracts.FileManager) *JSONLinesWriter {
+	return &JSONLinesWriter{m: m}
+}
+
+// Encode writes v as JSON followed by a newline. JSON strings escape their
+// newlines so that every value stays on its own line.
+func (w *JSONLinesWriter) Encode(v interface{}) error {
+	e := lineEncoders.Get().(*lineEncoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledEncoderSize {
+			e.buf.Reset()
+			lineEncoders.Put(e)
+		}
+	}()
+
+	if err := e.enc.Encode(v); err != nil {
+		return errors.Wrap(err, "unable to encode value")
+	}
+
+	if _, err := w.m.Write(e.buf.Bytes()); err != nil {
+		return errors.Wrap(err, "unable to write value")
+	}
+
+	return nil
+}
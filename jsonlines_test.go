@@ -0,0 +1,76 @@
+package gofile
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONLinesWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewJSONLinesWriter(&bufferManager{buf: buf})
+
+	err1 := w.Encode(map[string]string{"message": "hello\nworld"})
+	err2 := w.Encode([]int{1, 2})
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, "{\"message\":\"hello\\nworld\"}\n[1,2]\n", buf.String())
+}
+
+func TestJSONLinesWriterInvalidValue(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewJSONLinesWriter(&bufferManager{buf: buf})
+
+	err := w.Encode(make(chan int))
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestJSONLinesWriterOneWritePerValue(t *testing.T) {
+	var handled []string
+	mtx := sync.Mutex{}
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(100), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			mtx.Lock()
+			handled = append(handled, f.Path)
+			mtx.Unlock()
+		}),
+	)
+	w := NewJSONLinesWriter(rm)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_ = w.Encode(map[string]interface{}{"i": i, "padding": strings.Repeat("x", i)})
+		}(i)
+	}
+	wg.Wait()
+	rm.Close()
+
+	lines := 0
+	for _, path := range handled {
+		c, _ := ioutil.ReadFile(path)
+
+		for _, line := range strings.Split(string(c), "\n") {
+			if line == "" {
+				continue
+			}
+
+			var v map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &v))
+			lines++
+		}
+	}
+
+	assert.Equal(t, 50, lines)
+}
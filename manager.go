@@ -3,59 +3,319 @@ package gofile
 import (
 	"bufio"
 	"github.com/pkg/errors"
+	"hash"
+	"io"
 	"os"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Manager manages a file for writing, Manager is not threadsafe
 // If you need it to be threadsafe you should use the pool instead
 type Manager struct {
-	path    string
-	file    *os.File
-	writer  *bufio.Writer
-	written uint64
-	closed  bool
-	deleted bool
+	mtx        *sync.Mutex
+	path       string
+	file       *os.File
+	writer     *bufio.Writer
+	out        io.Writer
+	compressor io.WriteCloser
+	disk       *countingWriter
+	written    uint64
+	closed     bool
+	deleted    bool
+	codec      Codec
+	level      int
+	sync       SyncPolicy
+	unsynced   uint64
+	syncedAt   time.Time
+	syncTimer  *time.Timer
+	syncErr    error
+	hooks      []FileHooks
+	openedAt   time.Time
+	lastWrite  time.Time
+	writes     uint64
+	checksum   ChecksumAlgorithm
 }
 
-func NewManager(path string) (*Manager, error) {
+// SyncMode tells a Manager when to commit its file to stable storage.
+type SyncMode int
+
+const (
+	// SyncNever leaves it to the operating system, this is the default.
+	SyncNever SyncMode = iota
+	// SyncOnClose syncs the file when the Manager is closed.
+	SyncOnClose
+	// SyncEveryBytes syncs once SyncPolicy.Bytes have been written since the
+	// last sync, and on close.
+	SyncEveryBytes
+	// SyncEveryInterval syncs the data written at most SyncPolicy.Interval
+	// after the last sync, in the background when no write comes, and on
+	// close. Background sync errors are returned by the next Write.
+	SyncEveryInterval
+	// SyncAlways syncs after every write.
+	SyncAlways
+)
+
+// SyncPolicy configures the durability of a Manager.
+type SyncPolicy struct {
+	Mode     SyncMode
+	Bytes    uint64
+	Interval time.Duration
+}
+
+// ManagerOption configures a Manager when it is created.
+type ManagerOption func(m *Manager)
+
+// WithSyncPolicy sets when the Manager syncs its file, see SyncMode.
+func WithSyncPolicy(p SyncPolicy) ManagerOption {
+	return func(m *Manager) {
+		m.sync = p
+	}
+}
+
+// WithStreamCompression compresses the data on the fly. WrittenBytes still
+// reports uncompressed bytes while DiskBytes reports compressed ones.
+func WithStreamCompression(codec Codec, level int) ManagerOption {
+	return func(m *Manager) {
+		m.codec = codec
+		m.level = level
+	}
+}
+
+func NewManager(path string, opts ...ManagerOption) (*Manager, error) {
 	f, err := os.Create(path)
 
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create file")
 	}
 
-	return &Manager{
-		path:    path,
-		file:    f,
-		writer:  bufio.NewWriter(f),
-		written: 0,
-		closed:  false,
-		deleted: false,
-	}, nil
+	m := &Manager{
+		mtx:      &sync.Mutex{},
+		path:     path,
+		file:     f,
+		writer:   bufio.NewWriter(f),
+		written:  0,
+		closed:   false,
+		deleted:  false,
+		syncedAt: time.Now(),
+		openedAt: time.Now(),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.disk = &countingWriter{w: m.writer}
+	if m.checksum.New != nil {
+		m.disk.h = m.checksum.New()
+	}
+	m.out = m.disk
+
+	if m.codec != nil {
+		m.compressor, err = m.codec.NewWriter(m.disk, m.level)
+		if err != nil {
+			f.Close()
+			return nil, errors.Wrap(err, "unable to create compressor")
+		}
+
+		m.out = m.compressor
+	}
+
+	counted, _, err := runOpenHooks(m.hooks, m.out)
+	m.written += counted
+
+	if err != nil {
+		m.file.Close()
+		os.Remove(path)
+
+		return nil, err
+	}
+
+	return m, nil
 }
 
 func (m *Manager) Write(b []byte) (int, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
 	if m.closed != false || m.deleted != false {
 		return 0, errors.New("manager closed")
 	}
 
-	w, err := m.writer.Write(b)
+	w, err := m.out.Write(b)
 	if err != nil {
 		return 0, errors.Wrap(err, "unable to write to writer")
 	}
 
 	atomic.AddUint64(&m.written, uint64(w))
+	m.unsynced += uint64(w)
+	m.writes++
+	m.lastWrite = time.Now()
+
+	if m.syncErr != nil {
+		err, m.syncErr = m.syncErr, nil
+		return w, err
+	}
+
+	if m.shouldSync() {
+		if err := m.commit(); err != nil {
+			return w, err
+		}
+	}
+
+	m.scheduleSync()
 
 	return w, nil
 }
 
+func (m *Manager) shouldSync() bool {
+	switch m.sync.Mode {
+	case SyncAlways:
+		return true
+	case SyncEveryBytes:
+		return m.unsynced >= m.sync.Bytes
+	case SyncEveryInterval:
+		return time.Since(m.syncedAt) >= m.sync.Interval
+	}
+
+	return false
+}
+
+// scheduleSync syncs the data left unsynced once the interval expired, in
+// case no write comes by then.
+func (m *Manager) scheduleSync() {
+	if m.sync.Mode != SyncEveryInterval || m.unsynced == 0 || m.syncTimer != nil {
+		return
+	}
+
+	m.syncTimer = time.AfterFunc(time.Until(m.syncedAt.Add(m.sync.Interval)), func() {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+
+		m.syncTimer = nil
+		if m.closed || m.deleted || m.unsynced == 0 {
+			return
+		}
+
+		m.syncErr = m.commit()
+	})
+}
+
+// Flush writes the buffered data, including the data held by the stream
+// compressor, to the file.
+func (m *Manager) Flush() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.flush()
+}
+
+func (m *Manager) flush() error {
+	if m.closed != false || m.deleted != false {
+		return errors.New("manager closed")
+	}
+
+	if f, ok := m.compressor.(flusher); ok {
+		if err := f.Flush(); err != nil {
+			return errors.Wrap(err, "unable to flush compressor")
+		}
+	}
+
+	if err := m.writer.Flush(); err != nil {
+		return errors.Wrap(err, "unable to flush writer")
+	}
+
+	return nil
+}
+
+// Sync flushes the Manager and commits its file to stable storage.
+func (m *Manager) Sync() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.commit()
+}
+
+func (m *Manager) commit() error {
+	if err := m.flush(); err != nil {
+		return err
+	}
+
+	if err := m.file.Sync(); err != nil {
+		return errors.Wrap(err, "unable to sync file")
+	}
+
+	m.unsynced = 0
+	m.syncedAt = time.Now()
+
+	return nil
+}
+
+// syncFlushed commits the data already flushed without holding the lock, so
+// that writes go on meanwhile. The Manager must not be closed before it
+// returns.
+func (m *Manager) syncFlushed() error {
+	return errors.Wrap(m.file.Sync(), "unable to sync file")
+}
+
 func (m *Manager) WrittenBytes() uint64 {
 	return m.written
 }
 
+// DiskBytes returns the bytes written to the file once compressed, the
+// compressor buffers data so it lags behind WrittenBytes until Close.
+func (m *Manager) DiskBytes() uint64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.disk.n
+}
+
+// Close runs the OnClose hooks then closes the file, hook failures do not
+// prevent the file from being closed.
 func (m *Manager) Close() error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var herr error
+
+	if m.syncTimer != nil {
+		m.syncTimer.Stop()
+		m.syncTimer = nil
+	}
+
+	if m.closed != true && len(m.hooks) > 0 {
+		var counted uint64
+		counted, _, herr = runCloseHooks(m.hooks, m.out, m.stats)
+		m.written += counted
+		// Hooks run once even when closing fails.
+		m.hooks = nil
+	}
+
+	if err := m.close(); err != nil {
+		return err
+	}
+
+	return herr
+}
+
+func (m *Manager) close() error {
 	var err error
+
+	if m.compressor != nil {
+		c := m.compressor
+		m.compressor = nil
+
+		if err = c.Close(); err != nil {
+			m.writer.Flush()
+			m.file.Close()
+			m.closed = true
+
+			return errors.Wrap(err, "unable to close compressor")
+		}
+	}
+
 	err = m.writer.Flush()
 
 	if err != nil {
@@ -63,6 +323,15 @@ func (m *Manager) Close() error {
 	}
 
 	if m.closed != true {
+		if m.sync.Mode != SyncNever {
+			if err = m.file.Sync(); err != nil {
+				m.file.Close()
+				m.closed = true
+
+				return errors.Wrap(err, "unable to sync file")
+			}
+		}
+
 		err = m.file.Close()
 		m.closed = true
 
@@ -73,3 +342,38 @@ func (m *Manager) Close() error {
 
 	return nil
 }
+
+func (m *Manager) stats() FileStats {
+	return FileStats{
+		Path:         m.path,
+		OpenedAt:     m.openedAt,
+		LastWriteAt:  m.lastWrite,
+		WrittenBytes: m.written,
+		DiskBytes:    m.disk.n,
+		Writes:       m.writes,
+		Checksum:     m.digest(),
+	}
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// countingWriter counts, and optionally hashes, the bytes written to the
+// file.
+type countingWriter struct {
+	w io.Writer
+	n uint64
+	h hash.Hash
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += uint64(n)
+
+	if c.h != nil {
+		c.h.Write(b[:n])
+	}
+
+	return n, err
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -109,6 +110,141 @@ func TestCloseCanBeCalledMultipleTimes(t *testing.T) {
 	assert.NoError(t, err2)
 }
 
+func TestStreamCompression(t *testing.T) {
+	for _, codec := range []Codec{Gzip, Zstd, Snappy} {
+		fn := newFileName(t)
+		m, err := NewManager(fn, WithStreamCompression(codec, 0))
+		b := []byte(strings.Repeat("hello", 1000))
+
+		_, _ = m.Write(b)
+		m.Close()
+
+		info, _ := os.Stat(fn)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(len(b)), m.WrittenBytes())
+		assert.Equal(t, uint64(info.Size()), m.DiskBytes())
+		assert.Less(t, m.DiskBytes(), m.WrittenBytes())
+		assert.Equal(t, b, readCompressed(t, fn, codec))
+	}
+}
+
+func TestDiskBytesWithoutCompression(t *testing.T) {
+	m, _ := NewManager(newFileName(t))
+
+	m.Write([]byte("hello"))
+
+	assert.Equal(t, uint64(5), m.DiskBytes())
+}
+
+func TestStreamCompressionBrokenCodec(t *testing.T) {
+	m, err := NewManager(newFileName(t), WithStreamCompression(brokenCodec{}, 0))
+
+	assert.Nil(t, m)
+	assert.Error(t, err)
+}
+
+func TestManagerFlush(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn)
+
+	m.Write([]byte("hello"))
+	err := m.Flush()
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(c))
+}
+
+func TestManagerFlushesStreamCompressor(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn, WithStreamCompression(Gzip, 0))
+
+	m.Write([]byte("hello"))
+	err := m.Flush()
+	info, _ := os.Stat(fn)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(info.Size()), m.DiskBytes())
+	assert.NotZero(t, info.Size())
+}
+
+func TestManagerSyncAfterClose(t *testing.T) {
+	m, _ := NewManager(newFileName(t))
+
+	m.Close()
+
+	assert.Error(t, m.Sync())
+	assert.Error(t, m.Flush())
+}
+
+func TestSyncPolicies(t *testing.T) {
+	tests := []struct {
+		policy   SyncPolicy
+		synced   []bool
+		interval time.Duration
+	}{
+		{SyncPolicy{Mode: SyncNever}, []bool{false, false, false}, 0},
+		{SyncPolicy{Mode: SyncOnClose}, []bool{false, false, false}, 0},
+		{SyncPolicy{Mode: SyncAlways}, []bool{true, true, true}, 0},
+		{SyncPolicy{Mode: SyncEveryBytes, Bytes: 10}, []bool{false, true, false}, 0},
+		{SyncPolicy{Mode: SyncEveryInterval, Interval: time.Hour}, []bool{false, false, false}, 0},
+	}
+
+	for _, test := range tests {
+		fn := newFileName(t)
+		m, _ := NewManager(fn, WithSyncPolicy(test.policy))
+
+		for i, synced := range test.synced {
+			if i == 1 {
+				time.Sleep(test.interval)
+			}
+
+			m.Write([]byte("hello"))
+			c, _ := ioutil.ReadFile(fn)
+
+			assert.Equal(t, synced, len(c) == (i+1)*5, "policy %d write %d", test.policy.Mode, i)
+		}
+
+		assert.NoError(t, m.Close())
+	}
+}
+
+func TestSyncEveryIntervalWhileIdle(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn, WithSyncPolicy(SyncPolicy{Mode: SyncEveryInterval, Interval: time.Millisecond * 20}))
+	defer m.Close()
+
+	_, _ = m.Write([]byte("hello"))
+	before, _ := ioutil.ReadFile(fn)
+	time.Sleep(time.Millisecond * 50)
+	after, _ := ioutil.ReadFile(fn)
+
+	assert.Empty(t, before)
+	assert.Equal(t, "hello", string(after))
+}
+
+func TestSyncEveryIntervalOnWrite(t *testing.T) {
+	fn := newFileName(t)
+	m, _ := NewManager(fn, WithSyncPolicy(SyncPolicy{Mode: SyncEveryInterval, Interval: time.Millisecond * 20}))
+	defer m.Close()
+
+	m.syncedAt = time.Now().Add(-time.Second)
+	_, _ = m.Write([]byte("hello"))
+	c, _ := ioutil.ReadFile(fn)
+
+	assert.Equal(t, "hello", string(c))
+}
+
+func TestBrokenSync(t *testing.T) {
+	m, _ := NewManager(newFileName(t), WithSyncPolicy(SyncPolicy{Mode: SyncAlways}))
+	m.file.Close()
+
+	_, err := m.Write([]byte("hello"))
+
+	assert.Error(t, err)
+}
+
 func BenchmarkAppend(b *testing.B) {
 	fn := newFileName(b)
 	m, _ := NewManager(fn)
@@ -1,9 +1,14 @@
 package gofile
 
 import (
+	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/rs/xid"
 )
 
@@ -18,3 +23,210 @@ func NewRandFileName(dirPath, prefix string) string {
 		guid.String(),
 	)
 }
+
+// NameInfo holds what a NameStrategy can use to name a new file.
+type NameInfo struct {
+	Prefix string
+	// Time is the UTC time at which the file is opened.
+	Time time.Time
+	// Seq is incremented for every file opened by a RotatingManager.
+	Seq      uint64
+	Hostname string
+	PID      int
+}
+
+// NameStrategy names the files created by a RotatingManager, the returned
+// name is relative to the RotatingManager directory. Rotations to a name that
+// is already taken fail and are retried like any failed open.
+type NameStrategy interface {
+	Name(info NameInfo) (string, error)
+}
+
+// SequenceParser is implemented by name strategies able to read back the
+// sequence number of a file name, RotatingManager then resumes the sequence
+// after the highest one found in its directory.
+type SequenceParser interface {
+	ParseSeq(prefix, name string) (uint64, bool)
+}
+
+// NameMatcher is implemented by name strategies able to tell whether a file
+// name is one of theirs, RotatingManager then only recovers the files they
+// named.
+type NameMatcher interface {
+	MatchName(prefix, name string) bool
+}
+
+// NameStrategyFunc adapts a function to the NameStrategy interface.
+type NameStrategyFunc func(info NameInfo) (string, error)
+
+func (f NameStrategyFunc) Name(info NameInfo) (string, error) {
+	return f(info)
+}
+
+type randNameStrategy struct{}
+
+// RandNameStrategy names files prefix + timestamp + "_" + xid, this is the
+// default strategy.
+func RandNameStrategy() NameStrategy {
+	return randNameStrategy{}
+}
+
+func (randNameStrategy) Name(info NameInfo) (string, error) {
+	return fmt.Sprintf(
+		"%s%s_%s",
+		info.Prefix,
+		info.Time.Format("20060102150405"),
+		xid.New().String(),
+	), nil
+}
+
+func (randNameStrategy) MatchName(prefix, name string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+
+	parts := strings.Split(name[len(prefix):], "_")
+	if len(parts) != 2 {
+		return false
+	}
+
+	if _, err := time.Parse("20060102150405", parts[0]); err != nil {
+		return false
+	}
+
+	_, err := xid.FromString(parts[1])
+
+	return err == nil
+}
+
+// StrftimeNameStrategy names files prefix + the open time formatted with the
+// given strftime pattern, "%Y%m%d%H.log" gives "prefix2026101714.log".
+func StrftimeNameStrategy(pattern string) NameStrategy {
+	return NameStrategyFunc(func(info NameInfo) (string, error) {
+		return info.Prefix + Strftime(info.Time, pattern), nil
+	})
+}
+
+// TemplateNameStrategy names files by executing a text/template with the
+// NameInfo. Templates can use the strftime function on top of the builtins:
+//
+//	{{.Prefix}}{{strftime "%Y%m%d" .Time}}.{{.Hostname}}.{{printf "%06d" .Seq}}.ndjson.gz
+func TemplateNameStrategy(tmpl string) (NameStrategy, error) {
+	t, err := template.New("name").
+		Funcs(template.FuncMap{"strftime": templateStrftime}).
+		Parse(tmpl)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse name template")
+	}
+
+	return NameStrategyFunc(func(info NameInfo) (string, error) {
+		buf := &bytes.Buffer{}
+
+		if err := t.Execute(buf, info); err != nil {
+			return "", errors.Wrap(err, "unable to execute name template")
+		}
+
+		return buf.String(), nil
+	}), nil
+}
+
+func templateStrftime(pattern string, t time.Time) string {
+	return Strftime(t, pattern)
+}
+
+type sequenceNameStrategy struct {
+	width int
+	ext   string
+}
+
+// SequenceNameStrategy names files prefix + zero padded sequence + ext, a
+// "events." prefix with a width of 6 and a ".log" ext gives
+// "events.000123.log". Sequences resume where they stopped on restart.
+func SequenceNameStrategy(width int, ext string) NameStrategy {
+	return &sequenceNameStrategy{width: width, ext: ext}
+}
+
+func (s *sequenceNameStrategy) Name(info NameInfo) (string, error) {
+	return fmt.Sprintf("%s%0*d%s", info.Prefix, s.width, info.Seq, s.ext), nil
+}
+
+func (s *sequenceNameStrategy) MatchName(prefix, name string) bool {
+	_, ok := s.ParseSeq(prefix, name)
+
+	return ok
+}
+
+func (s *sequenceNameStrategy) ParseSeq(prefix, name string) (uint64, bool) {
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, s.ext) {
+		return 0, false
+	}
+
+	digits := name[len(prefix) : len(name)-len(s.ext)]
+	if len(digits) < s.width {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return seq, true
+}
+
+// Strftime formats t following the C strftime conventions, %Y %y %m %d %H
+// %I %M %S %L (milliseconds) %j %a %b %p %s %z %Z and %% are supported.
+func Strftime(t time.Time, pattern string) string {
+	buf := &strings.Builder{}
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i == len(pattern)-1 {
+			buf.WriteByte(pattern[i])
+			continue
+		}
+
+		i++
+		switch pattern[i] {
+		case 'Y':
+			buf.WriteString(t.Format("2006"))
+		case 'y':
+			buf.WriteString(t.Format("06"))
+		case 'm':
+			buf.WriteString(t.Format("01"))
+		case 'd':
+			buf.WriteString(t.Format("02"))
+		case 'H':
+			buf.WriteString(t.Format("15"))
+		case 'I':
+			buf.WriteString(t.Format("03"))
+		case 'M':
+			buf.WriteString(t.Format("04"))
+		case 'S':
+			buf.WriteString(t.Format("05"))
+		case 'L':
+			fmt.Fprintf(buf, "%03d", t.Nanosecond()/int(time.Millisecond))
+		case 'j':
+			fmt.Fprintf(buf, "%03d", t.YearDay())
+		case 'a':
+			buf.WriteString(t.Format("Mon"))
+		case 'b':
+			buf.WriteString(t.Format("Jan"))
+		case 'p':
+			buf.WriteString(t.Format("PM"))
+		case 's':
+			buf.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case 'z':
+			buf.WriteString(t.Format("-0700"))
+		case 'Z':
+			buf.WriteString(t.Format("MST"))
+		case '%':
+			buf.WriteByte('%')
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(pattern[i])
+		}
+	}
+
+	return buf.String()
+}
@@ -1,7 +1,9 @@
 package gofile
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -12,3 +14,77 @@ func TestNamePrefix(t *testing.T) {
 
 	assert.Contains(t, fn, "my_prefix")
 }
+
+func TestRandNameStrategy(t *testing.T) {
+	now := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+	n1, _ := RandNameStrategy().Name(NameInfo{Prefix: "events_", Time: now})
+	n2, _ := RandNameStrategy().Name(NameInfo{Prefix: "events_", Time: now})
+
+	assert.True(t, strings.HasPrefix(n1, "events_20261017140000_"))
+	assert.NotEqual(t, n1, n2)
+}
+
+func TestStrftimeNameStrategy(t *testing.T) {
+	now := time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC)
+	n, err := StrftimeNameStrategy("%Y-%m-%dT%H%M.log").Name(NameInfo{Prefix: "events.", Time: now})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "events.2026-10-17T1405.log", n)
+}
+
+func TestTemplateNameStrategy(t *testing.T) {
+	now := time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC)
+	s, err := TemplateNameStrategy(`{{.Prefix}}{{strftime "%Y%m%d" .Time}}.{{.Hostname}}.{{.PID}}.{{printf "%04d" .Seq}}.ndjson.gz`)
+	n, _ := s.Name(NameInfo{Prefix: "events.", Time: now, Seq: 12, Hostname: "host", PID: 42})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "events.20261017.host.42.0012.ndjson.gz", n)
+}
+
+func TestTemplateNameStrategyInvalid(t *testing.T) {
+	_, err := TemplateNameStrategy(`{{.Prefix`)
+
+	assert.Error(t, err)
+}
+
+func TestSequenceNameStrategy(t *testing.T) {
+	s := SequenceNameStrategy(6, ".log")
+	n, _ := s.Name(NameInfo{Prefix: "events.", Seq: 123})
+
+	assert.Equal(t, "events.000123.log", n)
+}
+
+func TestSequenceNameStrategyParseSeq(t *testing.T) {
+	s := SequenceNameStrategy(6, ".log").(SequenceParser)
+
+	seq, ok := s.ParseSeq("events.", "events.000123.log")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(123), seq)
+
+	_, ok = s.ParseSeq("events.", "events.000123.log.gz")
+	assert.False(t, ok)
+
+	_, ok = s.ParseSeq("events.", "other.000123.log")
+	assert.False(t, ok)
+
+	_, ok = s.ParseSeq("events.", "events.12.log")
+	assert.False(t, ok)
+}
+
+func TestRandNameStrategyMatchName(t *testing.T) {
+	s := RandNameStrategy().(NameMatcher)
+	name, _ := RandNameStrategy().Name(NameInfo{Prefix: "events_", Time: time.Now()})
+
+	assert.True(t, s.MatchName("events_", name))
+	assert.False(t, s.MatchName("other_", name))
+	assert.False(t, s.MatchName("events_", "events_1"))
+	assert.False(t, s.MatchName("events_", name+".log"))
+}
+
+func TestStrftime(t *testing.T) {
+	now := time.Date(2026, 2, 3, 16, 5, 9, 7*int(time.Millisecond), time.UTC)
+
+	assert.Equal(t, "26 034 04PM 007 %q 100%", Strftime(now, "%y %j %I%p %L %q 100%%"))
+	assert.Equal(t, "Tue Feb UTC +0000", Strftime(now, "%a %b %Z %z"))
+	assert.Equal(t, "trailing%", Strftime(now, "trailing%"))
+}
@@ -0,0 +1,84 @@
+package gofile
+
+import (
+	"github.com/paulhenri-l/gofile/contracts"
+)
+
+// RotatingManagerOption configures a RotatingManager before its first file is
+// opened.
+type RotatingManagerOption func(rm *RotatingManager)
+
+// WithNameStrategy sets how the files created by the RotatingManager are
+// named, RandNameStrategy is the default.
+func WithNameStrategy(s NameStrategy) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.names = s
+	}
+}
+
+// WithPartitioner writes files into the subdirectories returned by the
+// partitioner, they are created on demand. Partitions are derived from the
+// file open time, or from the record time when using WriteAt.
+func WithPartitioner(p Partitioner) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.partitioner = p
+	}
+}
+
+// WithInProgressSuffix writes the active file as name + suffix, it is synced
+// and atomically renamed to its final name once closed and before the
+// RotatedFileHandler is called.
+func WithInProgressSuffix(suffix string) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.inProgressSuffix = suffix
+	}
+}
+
+// WithRotatedFileHandler sets the handler at construction so that it also
+// receives the files recovered by WithRecovery.
+func WithRotatedFileHandler(h RotatedFileHandler) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.WithRotatedFileHandler(h)
+	}
+}
+
+// WithFileHandler sets the handler at construction, see
+// RotatingManager.WithFileHandler.
+func WithFileHandler(h contracts.RotatedFileHandler) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.fileHandler = h
+	}
+}
+
+// WithErrorHandler sets the error handler at construction so that it also
+// receives the errors happening during recovery.
+func WithErrorHandler(h ErrorHandler) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.errorHandler = h
+	}
+}
+
+// WithRecovery finalizes, at construction, the in progress files left behind
+// by a crash and hands them over to the RotatedFileHandler. The repairer,
+// which may be nil, can truncate a torn trailing record beforehand. Recovery
+// requires WithInProgressSuffix.
+//
+// Orphans are the in progress files whose name matches the NameStrategy when
+// it implements NameMatcher or SequenceParser, as the random and sequence
+// strategies do. With other strategies every in progress file starting with
+// the prefix is recovered, any in progress file of the directory when the
+// prefix is empty.
+func WithRecovery(repair Repairer) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.recover = true
+		rm.repairer = repair
+	}
+}
+
+// WithManagerOptions configures the Managers created by the default factory,
+// it has no effect when a factory is given.
+func WithManagerOptions(opts ...ManagerOption) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.managerOptions = append(rm.managerOptions, opts...)
+	}
+}
@@ -0,0 +1,36 @@
+package gofile
+
+import (
+	"time"
+)
+
+// Partitioner picks the directory, relative to the RotatingManager path, of
+// the files opened for a given time.
+type Partitioner interface {
+	Partition(t time.Time) string
+}
+
+// PartitionerFunc adapts a function to the Partitioner interface.
+type PartitionerFunc func(t time.Time) string
+
+func (f PartitionerFunc) Partition(t time.Time) string {
+	return f(t)
+}
+
+// HivePartitioner lays files out in Hive style partitions evaluated in loc,
+// dt=2026-10-17/hour=14 when hourly and dt=2026-10-17 otherwise.
+func HivePartitioner(hourly bool, loc *time.Location) Partitioner {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return PartitionerFunc(func(t time.Time) string {
+		t = t.In(loc)
+
+		if hourly {
+			return "dt=" + t.Format("2006-01-02") + "/hour=" + t.Format("15")
+		}
+
+		return "dt=" + t.Format("2006-01-02")
+	})
+}
@@ -0,0 +1,22 @@
+package gofile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHourlyHivePartitioner(t *testing.T) {
+	p := HivePartitioner(true, time.UTC)
+	now := time.Date(2026, 10, 17, 14, 5, 0, 0, time.UTC)
+
+	assert.Equal(t, "dt=2026-10-17/hour=14", p.Partition(now))
+}
+
+func TestDailyHivePartitionerInLocation(t *testing.T) {
+	p := HivePartitioner(false, time.FixedZone("UTC+2", 2*60*60))
+	now := time.Date(2026, 10, 17, 23, 5, 0, 0, time.UTC)
+
+	assert.Equal(t, "dt=2026-10-18", p.Partition(now))
+}
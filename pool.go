@@ -73,6 +73,46 @@ func (p *Pool) Close() error {
 	return nil
 }
 
+// Flush waits for the managers in use and flushes all of them.
+func (p *Pool) Flush() error {
+	return p.each(func(m contracts.FileManager) error {
+		return errors.Wrap(m.Flush(), "unable to flush manager")
+	})
+}
+
+// Sync waits for the managers in use and syncs all of them.
+func (p *Pool) Sync() error {
+	return p.each(func(m contracts.FileManager) error {
+		return errors.Wrap(m.Sync(), "unable to sync manager")
+	})
+}
+
+func (p *Pool) each(fn func(m contracts.FileManager) error) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.closed {
+		return errors.New("pool closed")
+	}
+
+	p.blocked = true
+
+	for len(p.managersPool) < p.size {
+		p.cnd.Wait()
+	}
+
+	defer p.cnd.Broadcast()
+	p.blocked = false
+
+	for _, m := range p.managersPool {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (p *Pool) take() contracts.FileManager {
 	p.mtx.Lock()
 	defer p.mtx.Unlock()
@@ -92,5 +132,5 @@ func (p *Pool) put(m contracts.FileManager) {
 	p.managersPool = append(p.managersPool, m)
 	p.mtx.Unlock()
 
-	p.cnd.Signal()
+	p.cnd.Broadcast()
 }
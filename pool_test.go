@@ -127,6 +127,45 @@ func TestFlushing(t *testing.T) {
 	assert.Contains(t, string(c), "Hello")
 }
 
+func TestPoolSyncWaitsForManagers(t *testing.T) {
+	m, tmp := newFakeManagers(t, 2)
+	p := NewPool(m)
+	wait := make(chan struct{})
+	_, _ = p.Write([]byte("Hello"))
+
+	go func() {
+		m1 := p.take()
+		wait <- struct{}{}
+		time.Sleep(50 * time.Millisecond)
+		p.put(m1)
+	}()
+
+	<-wait
+	err := p.Sync()
+
+	var c []byte
+	files, _ := ioutil.ReadDir(tmp)
+	for _, f := range files {
+		fc, _ := ioutil.ReadFile(fmt.Sprintf("%s/%s", tmp, f.Name()))
+		c = append(c, fc...)
+	}
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(c), "Hello")
+
+	_, err = p.Write([]byte("World"))
+	assert.NoError(t, err)
+}
+
+func TestPoolFlushAfterClose(t *testing.T) {
+	m, _ := newFakeManagers(t, 2)
+	p := NewPool(m)
+
+	_ = p.Close()
+
+	assert.EqualError(t, p.Flush(), "pool closed")
+}
+
 func TestWriteAfterClose(t *testing.T) {
 	m, _ := newFakeManagers(t, 2)
 	p := NewPool(m)
@@ -0,0 +1,60 @@
+package gofile
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WithRotateBefore also consults the policy before every write, with the
+// stats the file would have once written to. A write that would make a non
+// empty file exceed a SizePolicy then goes into a new file instead.
+func WithRotateBefore() RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.rotateBefore = true
+	}
+}
+
+// WriteRecord writes the parts of a single record, the record is never split
+// across files and counts as one write for the rotation policy. A single Write
+// is never split either, the record writers of this package rely on it.
+func (rm *RotatingManager) WriteRecord(parts ...[]byte) (int, error) {
+	return rm.write(time.Time{}, parts, nil)
+}
+
+// Record gathers the writes of a record built in several steps, see Begin.
+type Record struct {
+	rm        *RotatingManager
+	parts     [][]byte
+	committed bool
+}
+
+// Begin starts a record, the writes made to the record are buffered and only
+// written to the current file by Commit.
+func (rm *RotatingManager) Begin() *Record {
+	return &Record{rm: rm}
+}
+
+// Write appends a copy of b to the record.
+func (r *Record) Write(b []byte) (int, error) {
+	if r.committed {
+		return 0, errors.New("record committed")
+	}
+
+	c := make([]byte, len(b))
+	copy(c, b)
+	r.parts = append(r.parts, c)
+
+	return len(b), nil
+}
+
+// Commit writes the record as a whole, see WriteRecord.
+func (r *Record) Commit() (int, error) {
+	if r.committed {
+		return 0, errors.New("record committed")
+	}
+
+	r.committed = true
+
+	return r.rm.WriteRecord(r.parts...)
+}
@@ -0,0 +1,132 @@
+package gofile
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRecord(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(5), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	w, err := rm.WriteRecord([]byte("hel"), []byte("lo"), []byte("!"))
+	c, _ := ioutil.ReadFile(<-handled)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, w)
+	assert.Equal(t, "hello!", string(c))
+	assert.Equal(t, uint64(6), rm.WrittenBytes())
+
+	rm.Close()
+}
+
+func TestWriteRecordCountsOneWrite(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(t.TempDir(), "events_", CountPolicy(2), nil)
+	defer rm.Close()
+	m1 := rm.m
+
+	_, _ = rm.WriteRecord([]byte("hel"), []byte("lo"))
+
+	assert.Equal(t, m1, rm.m)
+	assert.Equal(t, uint64(1), rm.m.writes)
+}
+
+func TestRecordCommit(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(5), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+	r := rm.Begin()
+
+	_, _ = r.Write([]byte("hel"))
+	_, _ = r.Write([]byte("lo"))
+	_, _ = r.Write([]byte("!"))
+	w, err := r.Commit()
+	c, _ := ioutil.ReadFile(<-handled)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, w)
+	assert.Equal(t, "hello!", string(c))
+
+	rm.Close()
+}
+
+func TestRecordCannotBeReused(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(t.TempDir(), "events_", SizePolicy(5), nil)
+	defer rm.Close()
+	r := rm.Begin()
+
+	_, _ = r.Commit()
+	_, err1 := r.Write([]byte("hello"))
+	_, err2 := r.Commit()
+
+	assert.Error(t, err1)
+	assert.Error(t, err2)
+}
+
+func TestRotateBefore(t *testing.T) {
+	handled := make(chan string, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(10), nil,
+		WithRotateBefore(),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("worldwide"))
+	rm.Close()
+
+	c1, _ := ioutil.ReadFile(<-handled)
+	c2, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "hello", string(c1))
+	assert.Equal(t, "worldwide", string(c2))
+}
+
+func TestRotateBeforeKeepsLargeRecordsInEmptyFiles(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(5), nil,
+		WithRotateBefore(),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello world"))
+	c, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "hello world", string(c))
+
+	rm.Close()
+}
+
+func TestRotateAfterByDefault(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(10), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("worldwide"))
+	c, _ := ioutil.ReadFile(<-handled)
+
+	assert.Equal(t, "helloworldwide", string(c))
+
+	rm.Close()
+}
@@ -0,0 +1,197 @@
+package gofile
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Retention bounds the finalized files kept in the directory of a
+// RotatingManager, the oldest files are deleted first. Only the files named by
+// the NameStrategy count. Zero values mean no limit. Files are only deleted
+// once handed over, the files waiting for their compression or their handler
+// are kept and so are the files whose handler failed.
+type Retention struct {
+	MaxFiles int
+	MaxAge   time.Duration
+	MaxBytes uint64
+	// DryRun reports the files that would be deleted without deleting them.
+	DryRun bool
+	// OnDelete receives the deleted paths, or the ones that would have been
+	// deleted when running dry.
+	OnDelete func(paths []string)
+}
+
+// WithRetention prunes the directory after every rotation.
+func WithRetention(r Retention) RotatingManagerOption {
+	return func(rm *RotatingManager) {
+		rm.retention = &r
+	}
+}
+
+type retainedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// prune applies the retention in the background, prunes never overlap.
+func (rm *RotatingManager) prune() {
+	if rm.retention == nil {
+		return
+	}
+
+	rm.background.Add(1)
+	go func() {
+		defer rm.background.Done()
+
+		rm.pruneMtx.Lock()
+		defer rm.pruneMtx.Unlock()
+
+		if err := rm.applyRetention(time.Now()); err != nil {
+			rm.reportError(errors.Wrap(err, "unable to apply retention"))
+		}
+	}()
+}
+
+func (rm *RotatingManager) applyRetention(now time.Time) error {
+	files, err := rm.finalizedFiles()
+	if err != nil {
+		return err
+	}
+
+	// The active file is read once the directory was walked, a file opened
+	// by a rotation happening during the walk would be pruned otherwise. So
+	// are the unhandled files, a rotation hands its file over under the lock.
+	rm.mtx.Lock()
+	if !rm.stopped {
+		files = withoutFile(files, rm.m.activePath)
+	}
+	rm.mtx.Unlock()
+
+	files = rm.withoutUnhandled(files)
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().After(files[j].info.ModTime())
+	})
+
+	var total uint64
+	var expired []string
+	r := rm.retention
+
+	for i, f := range files {
+		total += uint64(f.info.Size())
+
+		if (r.MaxFiles > 0 && i >= r.MaxFiles) ||
+			(r.MaxBytes > 0 && total > r.MaxBytes) ||
+			(r.MaxAge > 0 && now.Sub(f.info.ModTime()) > r.MaxAge) {
+			expired = append(expired, f.path)
+		}
+	}
+
+	var deleted []string
+	for i := len(expired) - 1; i >= 0; i-- {
+		if !r.DryRun {
+			if err = os.Remove(expired[i]); err != nil {
+				break
+			}
+
+			rm.removeSidecar(expired[i])
+		}
+
+		deleted = append(deleted, expired[i])
+	}
+
+	if len(deleted) > 0 && r.OnDelete != nil {
+		r.OnDelete(deleted)
+	}
+
+	return errors.Wrap(err, "unable to delete file")
+}
+
+// finalizedFiles lists the files of the directory created by the manager,
+// in progress files and compressions excluded.
+func (rm *RotatingManager) finalizedFiles() ([]retainedFile, error) {
+	var files []retainedFile
+
+	err := rm.walkFiles(func(path string, info os.FileInfo) {
+		name := info.Name()
+		if rm.compression != nil {
+			name = strings.TrimSuffix(name, rm.compression.Codec.Extension())
+		}
+
+		if !rm.matchName(name) {
+			return
+		}
+
+		if rm.inProgressSuffix != "" && strings.HasSuffix(name, rm.inProgressSuffix) {
+			return
+		}
+
+		if strings.HasSuffix(name, ".tmp") {
+			return
+		}
+
+		files = append(files, retainedFile{path: path, info: info})
+	})
+
+	return files, err
+}
+
+func withoutFile(files []retainedFile, path string) []retainedFile {
+	for i, f := range files {
+		if f.path == path {
+			return append(files[:i], files[i+1:]...)
+		}
+	}
+
+	return files
+}
+
+// withoutUnhandled drops the files handed over and not handled yet, they may
+// be queued or still being compressed or handled.
+func (rm *RotatingManager) withoutUnhandled(files []retainedFile) []retainedFile {
+	kept := files[:0]
+
+	for _, f := range files {
+		if !rm.unhandled.has(f.path) {
+			kept = append(kept, f)
+		}
+	}
+
+	return kept
+}
+
+// pathSet is a set of paths safe for concurrent use.
+type pathSet struct {
+	mtx   *sync.Mutex
+	paths map[string]bool
+}
+
+func newPathSet() *pathSet {
+	return &pathSet{mtx: &sync.Mutex{}, paths: map[string]bool{}}
+}
+
+func (s *pathSet) add(path string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.paths[path] = true
+}
+
+func (s *pathSet) remove(path string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	delete(s.paths, path)
+}
+
+func (s *pathSet) has(path string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.paths[path]
+}
@@ -0,0 +1,187 @@
+package gofile
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionMaxFiles(t *testing.T) {
+	var deleted []string
+	tmp := newRetainedFiles(t, 3)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{
+			MaxFiles: 2,
+			OnDelete: func(paths []string) {
+				deleted = append(deleted, paths...)
+			},
+		}),
+	)
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	assert.Equal(t, []string{tmp + "/events_0", tmp + "/events_1", tmp + "/events_2"}, deleted)
+	assert.Len(t, listFiles(tmp), 2)
+}
+
+func TestRetentionMaxAge(t *testing.T) {
+	tmp := newRetainedFiles(t, 3)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{MaxAge: 150 * time.Minute}),
+	)
+	rm.Close()
+
+	assert.NotContains(t, listFiles(tmp), "events_0")
+	assert.Contains(t, listFiles(tmp), "events_1")
+	assert.Contains(t, listFiles(tmp), "events_2")
+}
+
+func TestRetentionMaxBytes(t *testing.T) {
+	tmp := newRetainedFiles(t, 3)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{MaxBytes: 11}),
+	)
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	assert.NotContains(t, listFiles(tmp), "events_0")
+	assert.NotContains(t, listFiles(tmp), "events_1")
+	assert.Contains(t, listFiles(tmp), "events_2")
+}
+
+func TestRetentionDryRun(t *testing.T) {
+	var deleted []string
+	tmp := newRetainedFiles(t, 3)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{
+			MaxFiles: 1,
+			DryRun:   true,
+			OnDelete: func(paths []string) {
+				deleted = append(deleted, paths...)
+			},
+		}),
+	)
+	rm.Close()
+
+	assert.Len(t, deleted, 3)
+	assert.Len(t, listFiles(tmp), 4)
+}
+
+func TestRetentionIgnoresOtherFiles(t *testing.T) {
+	tmp := newRetainedFiles(t, 1)
+	_ = ioutil.WriteFile(tmp+"/other", []byte("other"), 0644)
+	_ = ioutil.WriteFile(tmp+"/events_notes", []byte("notes"), 0644)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{MaxFiles: 1}),
+	)
+	rm.Close()
+
+	assert.Contains(t, listFiles(tmp), "other")
+	assert.Contains(t, listFiles(tmp), "events_notes")
+}
+
+func TestRetentionMatchesCompressedFiles(t *testing.T) {
+	tmp := newRetainedFiles(t, 2)
+	_ = os.Rename(tmp+"/events_0", tmp+"/events_0.gz")
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithCompression(Compression{Codec: Gzip}),
+		WithRetention(Retention{MaxFiles: 1}),
+	)
+	rm.Close()
+
+	assert.NotContains(t, listFiles(tmp), "events_0.gz")
+	assert.Len(t, listFiles(tmp), 1)
+}
+
+func TestRetentionKeepsUnhandledFiles(t *testing.T) {
+	var missing []string
+	handled := make(chan bool, 6)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+		WithRetention(Retention{MaxFiles: 1}),
+		WithHandlerWorkers(1, 10),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			time.Sleep(10 * time.Millisecond)
+
+			if _, err := os.Stat(f.Path); err != nil {
+				missing = append(missing, f.Path)
+			}
+
+			handled <- true
+		}),
+	)
+
+	for i := 0; i < 5; i++ {
+		_, _ = rm.Write([]byte("hello"))
+	}
+
+	rm.Close()
+
+	assert.Len(t, handled, 6)
+	assert.Empty(t, missing)
+	assert.Len(t, listFiles(rm.path), 1)
+}
+
+func TestRetentionIgnoresCompressionsInProgress(t *testing.T) {
+	tmp := newRetainedFiles(t, 1)
+	mtime := time.Now().Add(-2 * time.Hour)
+	_ = ioutil.WriteFile(tmp+"/events_1.gz.tmp", []byte("hello"), 0644)
+	_ = os.Chtimes(tmp+"/events_1.gz.tmp", mtime, mtime)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(1, "")),
+		WithRetention(Retention{MaxFiles: 1}),
+	)
+	rm.Close()
+
+	assert.Contains(t, listFiles(tmp), "events_1.gz.tmp")
+}
+
+// newRetainedFiles creates n files of 5 bytes, events_0 being the oldest.
+func newRetainedFiles(t *testing.T, n int) string {
+	tmp := t.TempDir()
+
+	for i := 0; i < n; i++ {
+		fn := fmt.Sprintf("%s/events_%d", tmp, i)
+		mtime := time.Now().Add(-time.Duration(n-i) * time.Hour)
+
+		_ = ioutil.WriteFile(fn, []byte("hello"), 0644)
+		_ = os.Chtimes(fn, mtime, mtime)
+	}
+
+	return tmp
+}
+
+func listFiles(dir string) []string {
+	var names []string
+	files, _ := ioutil.ReadDir(dir)
+
+	for _, f := range files {
+		names = append(names, f.Name())
+	}
+
+	return names
+}
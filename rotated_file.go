@@ -0,0 +1,132 @@
+package gofile
+
+import (
+	"hash"
+	"os"
+	"time"
+)
+
+// RotationReason tells why a file was rotated.
+type RotationReason int
+
+const (
+	// ReasonUnknown is the zero RotationReason.
+	ReasonUnknown RotationReason = iota
+	// ReasonSize is used when the policy was met by the bytes or the records
+	// written.
+	ReasonSize
+	// ReasonTime is used when a scheduled policy was due or when a record
+	// belonged to another partition.
+	ReasonTime
+	// ReasonManual is used for the rotations requested through Rotate.
+	ReasonManual
+	// ReasonClose is used for the last file of a RotatingManager.
+	ReasonClose
+	// ReasonRecovered is used for the files left over by a previous run,
+	// orphans recovered at construction or files replayed from the journal.
+	ReasonRecovered
+)
+
+func (r RotationReason) String() string {
+	switch r {
+	case ReasonSize:
+		return "size"
+	case ReasonTime:
+		return "time"
+	case ReasonManual:
+		return "manual"
+	case ReasonClose:
+		return "close"
+	case ReasonRecovered:
+		return "recovered"
+	}
+
+	return "unknown"
+}
+
+// RotatedFile describes a file handed over to the rotated file handlers.
+// Files recovered from a previous run are only described by what their
+// path and stat tell.
+type RotatedFile struct {
+	Path     string
+	OpenedAt time.Time
+	ClosedAt time.Time
+	// WrittenBytes excludes the bytes written by hooks unless counted, as
+	// in FileStats.
+	WrittenBytes uint64
+	// DiskBytes is the size of the file as handed over.
+	DiskBytes uint64
+	// Records counts the writes, a record written in parts counting as one.
+	Records uint64
+	// Checksum is set when the manager computes one, see WithChecksum. Files
+	// compressed after their rotation get the checksum of the compressed file,
+	// computed with the same algorithm, or none when the algorithm is neither
+	// a builtin one nor the one of WithChecksumSidecar.
+	Checksum Checksum
+	Reason   RotationReason
+	// Seq is the sequence number the file was named with.
+	Seq uint64
+}
+
+// rotatedFile describes dm once closed and finalized, the written bytes are
+// read again since close hooks may have written.
+func rotatedFile(dm *decoratedManager, reason RotationReason) RotatedFile {
+	stats := dm.stats()
+
+	f := RotatedFile{
+		Path:         dm.path,
+		OpenedAt:     dm.openedAt,
+		ClosedAt:     time.Now(),
+		WrittenBytes: stats.WrittenBytes,
+		DiskBytes:    stats.DiskBytes,
+		Records:      stats.Writes,
+		Reason:       reason,
+		Seq:          dm.seq,
+	}
+
+	if c, ok := dm.FileManager.(checksummer); ok {
+		f.Checksum = c.Checksum()
+	}
+
+	return f
+}
+
+// recoveredFile describes a file left over by a previous run.
+func recoveredFile(path string) RotatedFile {
+	f := RotatedFile{Path: path, Reason: ReasonRecovered}
+
+	if info, err := os.Stat(path); err == nil {
+		f.ClosedAt = info.ModTime()
+		f.DiskBytes = uint64(info.Size())
+	}
+
+	return f
+}
+
+// compressed describes the compressed copy of f found at path, h holds its
+// checksum if any.
+func (f RotatedFile) compressed(path string, h hash.Hash) RotatedFile {
+	f.Path = path
+
+	if h != nil {
+		f.Checksum = Checksum{Algorithm: f.Checksum.Algorithm, Sum: h.Sum(nil)}
+	} else {
+		f.Checksum = Checksum{}
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		f.DiskBytes = uint64(info.Size())
+	}
+
+	return f
+}
+
+// policyReason tells whether the policy met with stats at now is due to a
+// schedule or to the data written.
+func (rm *RotatingManager) policyReason(stats FileStats, now time.Time) RotationReason {
+	if next := nextCheck(rm.policy, stats); !next.IsZero() && !now.Before(next) {
+		return ReasonTime
+	}
+
+	return ReasonSize
+}
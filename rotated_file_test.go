@@ -0,0 +1,135 @@
+package gofile
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatedFileDescribesFile(t *testing.T) {
+	handled := make(chan RotatedFile, 3)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(10), nil,
+		WithNameStrategy(SequenceNameStrategy(6, ".log")),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f
+		}),
+	)
+	path := rm.m.path
+	before := time.Now()
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("world"))
+	_, _ = rm.Write([]byte("!"))
+	rm.Close()
+	first, last := <-handled, <-handled
+
+	assert.Equal(t, path, first.Path)
+	assert.Equal(t, uint64(10), first.WrittenBytes)
+	assert.Equal(t, uint64(10), first.DiskBytes)
+	assert.Equal(t, uint64(2), first.Records)
+	assert.Equal(t, ReasonSize, first.Reason)
+	assert.Equal(t, uint64(0), first.Seq)
+	assert.False(t, first.OpenedAt.After(before))
+	assert.False(t, first.ClosedAt.Before(first.OpenedAt))
+
+	assert.Equal(t, uint64(1), last.WrittenBytes)
+	assert.Equal(t, uint64(1), last.Records)
+	assert.Equal(t, ReasonClose, last.Reason)
+	assert.Equal(t, uint64(1), last.Seq)
+}
+
+func TestRotatedFileManualReason(t *testing.T) {
+	handled := make(chan RotatedFile, 2)
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f
+		}),
+	)
+	defer rm.Close()
+
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Rotate(context.Background())
+
+	assert.Equal(t, ReasonManual, (<-handled).Reason)
+}
+
+func TestRotatedFileTimeReason(t *testing.T) {
+	handled := make(chan RotatedFile, 2)
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", AnyPolicy(SizePolicy(1000), AgePolicy(time.Millisecond)), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f
+		}),
+	)
+	defer rm.Close()
+
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.Equal(t, ReasonTime, (<-handled).Reason)
+}
+
+func TestRotatedFileRecoveredReason(t *testing.T) {
+	dir := t.TempDir()
+	rotated := filepath.Join(dir, "events_1")
+	_ = ioutil.WriteFile(rotated, []byte("hello"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "journal"), []byte("+"+rotated+"\n"), 0644)
+
+	var handled RotatedFile
+	rm, _ := NewRotatingManager(
+		dir, "events_", time.Second*100, 1000,
+		WithJournal("journal"),
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = f
+		}),
+	)
+
+	assert.Equal(t, rotated, handled.Path)
+	assert.Equal(t, ReasonRecovered, handled.Reason)
+	assert.Equal(t, uint64(5), handled.DiskBytes)
+
+	rm.Close()
+}
+
+func TestRotationEventHandlerIsPreferred(t *testing.T) {
+	h := &rotationEventHandler{}
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithFileHandler(h),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	assert.Equal(t, 0, h.handled)
+	assert.Len(t, h.files, 1)
+	assert.Equal(t, uint64(5), h.files[0].WrittenBytes)
+}
+
+func TestRotationReasonString(t *testing.T) {
+	assert.Equal(t, "size", ReasonSize.String())
+	assert.Equal(t, "recovered", ReasonRecovered.String())
+	assert.Equal(t, "unknown", RotationReason(42).String())
+}
+
+type rotationEventHandler struct {
+	handled int
+	files   []RotatedFile
+}
+
+func (h *rotationEventHandler) Handle(path string) error {
+	h.handled++
+
+	return nil
+}
+
+func (h *rotationEventHandler) HandleRotation(f RotatedFile) error {
+	h.files = append(h.files, f)
+
+	return nil
+}
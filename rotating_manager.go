@@ -2,18 +2,62 @@ package gofile
 
 import (
 	"context"
-	"github.com/pkg/errors"
-	"github.com/paulhenri-l/gofile/chans"
 	"github.com/paulhenri-l/gofile/contracts"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
-type RotatedFileHandler func(path string)
+// RotatedFileHandler receives the files handed over by a RotatingManager.
+type RotatedFileHandler func(f RotatedFile)
+
+// DegradedMode tells a RotatingManager how to behave when a new file could
+// not be opened during a rotation.
+type DegradedMode int
+
+const (
+	// KeepWriting keeps writing to the current file while the open of a new
+	// file is retried with backoff.
+	KeepWriting DegradedMode = iota
+
+	// RefuseWrites makes Write return ErrDegraded until a new file could be
+	// opened, the open is retried with backoff.
+	RefuseWrites
+)
 
 type decoratedManager struct {
 	contracts.FileManager
-	path string
+	path        string
+	activePath  string
+	partition   string
+	openedAt    time.Time
+	lastWriteAt time.Time
+	writes      uint64
+	written     uint64
+	disk        uint64
+	hookBytes   uint64
+	info        os.FileInfo
+	seq         uint64
+	// syncs counts the fsyncs running outside rm.mtx, the file is only
+	// closed once they are done.
+	syncs sync.WaitGroup
+}
+
+// diskSizer is implemented by managers whose on disk size differs from the
+// written bytes.
+type diskSizer interface {
+	DiskBytes() uint64
+}
+
+// flushedSyncer is implemented by managers able to commit the data already
+// flushed while writes go on.
+type flushedSyncer interface {
+	syncFlushed() error
 }
 
 type RotatingManager struct {
@@ -21,13 +65,54 @@ type RotatingManager struct {
 	mtx                *sync.Mutex
 	path               string
 	prefix             string
+	names              NameStrategy
+	nameInfo           NameInfo
+	partitioner        Partitioner
+	partitionAt        time.Time
+	inProgressSuffix   string
+	recover            bool
+	repairer           Repairer
+	retention          *Retention
+	compression        *Compression
+	compressions       chan RotatedFile
+	compressors        *sync.WaitGroup
+	pruneMtx           *sync.Mutex
+	unhandled          *pathSet
+	handOvers          []RotatedFile
+	dispatchMtx        *sync.Mutex
+	background         *sync.WaitGroup
 	factory            ManagerFactory
-	rotateTime         time.Duration
-	rotateTicker       *time.Ticker
-	rotateSize         uint64
-	rotatedFileHandler RotatedFileHandler
+	managerOptions     []ManagerOption
+	fileHooks          []FileHooks
+	policy             RotationPolicy
+	rotateBefore       bool
+	fileHandler        contracts.RotatedFileHandler
+	handlerMtx         *sync.Mutex
+	unclaimed          []RotatedFile
+	handlerRetry       HandlerRetry
+	deadLetterHandler  DeadLetterHandler
+	handlerWorkers     int
+	handlerQueueSize   int
+	handlerQueue       chan RotatedFile
+	handlers           *sync.WaitGroup
+	journalPath        string
+	journal            *journal
+	sidecar            *sidecar
+	errorHandler       ErrorHandler
+	errMtx             *sync.Mutex
+	degradedMode       DegradedMode
+	backoff            Backoff
+	degraded           bool
+	retries            int
+	retryReason        RotationReason
+	retryAt            time.Time
 	stopped            bool
+	wake               chan struct{}
 	done               chan bool
+	signalsDone        chan bool
+	acks               []chan error
+	commits            chan struct{}
+	committed          chan bool
 	ctx                context.Context
 	cancel             context.CancelFunc
 	writtenBytes       uint64
@@ -38,13 +123,10 @@ func NewRotatingManager(
 	prefix string,
 	rotateTime time.Duration,
 	rotateSize uint64,
+	opts ...RotatingManagerOption,
 ) (*RotatingManager, error) {
-	f := func(path string) (contracts.FileManager, error) {
-		return NewManager(path)
-	}
-
 	return NewRotatingManagerWithFactory(
-		path, prefix, rotateTime, rotateSize, f,
+		path, prefix, rotateTime, rotateSize, nil, opts...,
 	)
 }
 
@@ -54,129 +136,896 @@ func NewRotatingManagerWithFactory(
 	rotateTime time.Duration,
 	rotateSize uint64,
 	f ManagerFactory,
+	opts ...RotatingManagerOption,
 ) (*RotatingManager, error) {
-	m, err := newDecoratedManager(path, prefix, f)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to create new manager")
-	}
+	policy := AnyPolicy(SizePolicy(rotateSize), AgePolicy(rotateTime))
+
+	return NewRotatingManagerWithPolicy(path, prefix, policy, f, opts...)
+}
 
+// NewRotatingManagerWithPolicy creates a RotatingManager whose rotations are
+// decided by the given policy, a nil factory creates plain Managers.
+func NewRotatingManagerWithPolicy(
+	path,
+	prefix string,
+	policy RotationPolicy,
+	f ManagerFactory,
+	opts ...RotatingManagerOption,
+) (*RotatingManager, error) {
 	rm := &RotatingManager{
-		m:          m,
-		prefix:     prefix,
-		path:       path,
-		mtx:        &sync.Mutex{},
-		factory:    f,
-		rotateTime: rotateTime,
-		rotateSize: rotateSize,
-		stopped:    false,
-		done:       make(chan bool),
+		prefix:      prefix,
+		path:        path,
+		names:       RandNameStrategy(),
+		mtx:         &sync.Mutex{},
+		errMtx:      &sync.Mutex{},
+		handlerMtx:  &sync.Mutex{},
+		pruneMtx:    &sync.Mutex{},
+		unhandled:   newPathSet(),
+		dispatchMtx: &sync.Mutex{},
+		background:  &sync.WaitGroup{},
+		factory:     f,
+		policy:      policy,
+		backoff:     DefaultBackoff,
+		stopped:     false,
+		wake:        make(chan struct{}, 1),
+		done:        make(chan bool),
+		commits:     make(chan struct{}, 1),
+		committed:   make(chan bool),
 	}
 
+	if f == nil {
+		rm.factory = rm.newManager
+	}
+
+	for _, opt := range opts {
+		opt(rm)
+	}
+
+	rm.startHandlers()
+	rm.startCompressors()
+
+	if err := rm.openJournal(); err != nil {
+		rm.stopWorkers()
+		return nil, errors.Wrap(err, "unable to open journal")
+	}
+
+	if err := rm.recoverOrphans(); err != nil {
+		rm.stopWorkers()
+		return nil, errors.Wrap(err, "unable to recover orphaned files")
+	}
+
+	rm.dispatchHandOvers()
+
+	if err := rm.initNames(); err != nil {
+		rm.stopWorkers()
+		return nil, errors.Wrap(err, "unable to initialize file names")
+	}
+
+	m, err := rm.newDecoratedManager()
+	if err != nil {
+		rm.stopWorkers()
+		return nil, errors.Wrap(err, "unable to create new manager")
+	}
+
+	rm.m = m
 	rm.start()
 
 	return rm, nil
 }
 
 func (rm *RotatingManager) WithRotatedFileHandler(h RotatedFileHandler) {
-	rm.rotatedFileHandler = h
+	if h == nil {
+		rm.setFileHandler(nil)
+		return
+	}
+
+	rm.setFileHandler(h)
+}
+
+// WithFileHandler sets a handler whose errors are retried following the
+// HandlerRetry policy.
+func (rm *RotatingManager) WithFileHandler(h contracts.RotatedFileHandler) {
+	rm.setFileHandler(h)
+}
+
+// setFileHandler sets the handler and hands it the journaled files that were
+// waiting for one.
+func (rm *RotatingManager) setFileHandler(h contracts.RotatedFileHandler) {
+	rm.handlerMtx.Lock()
+	rm.fileHandler = h
+
+	var waiting []RotatedFile
+	if h != nil {
+		waiting, rm.unclaimed = rm.unclaimed, nil
+	}
+	rm.handlerMtx.Unlock()
+
+	for _, f := range waiting {
+		rm.notifyRotationHandler(f)
+	}
+}
+
+// WithErrorHandler sets the handler receiving rotation errors.
+func (rm *RotatingManager) WithErrorHandler(h ErrorHandler) {
+	rm.errMtx.Lock()
+	defer rm.errMtx.Unlock()
+
+	rm.errorHandler = h
+}
+
+// WithDegradedMode sets how writes are handled while a new file cannot be
+// opened, KeepWriting is the default.
+func (rm *RotatingManager) WithDegradedMode(mode DegradedMode) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	rm.degradedMode = mode
+}
+
+// WithRetryBackoff sets the delays between two attempts at opening a new file
+// after a failed rotation.
+func (rm *RotatingManager) WithRetryBackoff(b Backoff) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	rm.backoff = b
+}
+
+// Degraded reports whether the last rotation failed to open a new file.
+func (rm *RotatingManager) Degraded() bool {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	return rm.degraded
 }
 
 func (rm *RotatingManager) Write(b []byte) (int, error) {
+	return rm.write(time.Time{}, [][]byte{b}, nil)
+}
+
+// WriteAt writes a record whose timestamp is t. With a Partitioner the record
+// goes into a file of the partition of t, rotating if the current file
+// belongs to another partition.
+func (rm *RotatingManager) WriteAt(t time.Time, b []byte) (int, error) {
+	return rm.write(t, [][]byte{b}, nil)
+}
+
+// WriteAck writes b and returns a channel receiving nil once b has been
+// synced to disk, or the error preventing it. Concurrent writes are synced
+// together so that a single fsync acknowledges many of them.
+func (rm *RotatingManager) WriteAck(b []byte) <-chan error {
+	ack := make(chan error, 1)
+
+	if _, err := rm.write(time.Time{}, [][]byte{b}, ack); err != nil {
+		ack <- err
+	}
+
+	return ack
+}
+
+// WriteSync writes b and waits for it to be synced to disk, see WriteAck.
+// When ctx is done first the write may still become durable later.
+func (rm *RotatingManager) WriteSync(ctx context.Context, b []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-rm.WriteAck(b):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// write writes the parts of a single record, rotations only happen before or
+// after the whole record.
+func (rm *RotatingManager) write(t time.Time, parts [][]byte, ack chan error) (int, error) {
+	rm.mtx.Lock()
+	defer rm.unlock()
+
 	if rm.stopped {
 		return 0, errors.New("rotating manager stopped")
 	}
 
-	rm.mtx.Lock()
-	defer rm.mtx.Unlock()
+	size := 0
+	for _, b := range parts {
+		size += len(b)
+	}
+
+	// Files opened before the write are partitioned by the record time.
+	rm.partitionAt = t
+	degraded := rm.beforeWrite(t, size)
+	rm.partitionAt = time.Time{}
+
+	if degraded {
+		return 0, ErrDegraded
+	}
+
+	w, err := rm.m.writeParts(parts)
+	rm.writtenBytes = rm.writtenBytes + uint64(w)
 
-	w, err := rm.m.Write(b)
 	if err != nil {
 		return w, errors.Wrap(err, "unable to write to manager")
 	}
 
-	rm.writtenBytes = rm.writtenBytes + uint64(w)
+	if ack != nil {
+		rm.acks = append(rm.acks, ack)
+		rm.requestCommit()
+	}
+
+	if rm.m.writes == 1 {
+		// Time based rotations are skipped while the file is empty.
+		rm.wakeUp()
+	}
 
-	if rm.m.WrittenBytes() >= rm.rotateSize {
-		rm.rotate()
+	if now, stats := time.Now(), rm.m.stats(); rm.policy.ShouldRotate(stats, now) {
+		rm.tryRotate(rm.policyReason(stats, now))
 	}
 
 	return w, nil
 }
 
+// beforeWrite rotates when needed before writing a record timestamped t, it
+// returns true when the write must be refused.
+func (rm *RotatingManager) beforeWrite(t time.Time, size int) bool {
+	if rm.degraded {
+		rm.tryRotate(rm.retryReason)
+
+		if rm.degraded && rm.degradedMode == RefuseWrites {
+			return true
+		}
+	}
+
+	// Scheduled rotations may be due before the ticker noticed, rotating now
+	// keeps this write out of a file belonging to a previous period.
+	if now, stats := time.Now(), rm.m.cachedStats(); rm.policy.ShouldRotate(stats, now) {
+		rm.tryRotate(rm.policyReason(stats, now))
+	}
+
+	if rm.partitioner != nil && !t.IsZero() && rm.partitioner.Partition(t) != rm.m.partition {
+		rm.tryRotate(ReasonTime)
+	}
+
+	if rm.rotateBefore && rm.m.writes > 0 {
+		now := time.Now()
+		projected := rm.m.cachedStats()
+		projected.WrittenBytes += uint64(size)
+		projected.DiskBytes += uint64(size)
+		projected.Writes++
+		projected.LastWriteAt = now
+
+		if rm.policy.ShouldRotate(projected, now) {
+			rm.tryRotate(rm.policyReason(projected, now))
+		}
+	}
+
+	return false
+}
+
 func (rm *RotatingManager) WrittenBytes() uint64 {
 	return rm.writtenBytes
 }
 
+// Flush flushes the current file.
+func (rm *RotatingManager) Flush() error {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	if rm.stopped {
+		return errors.New("rotating manager stopped")
+	}
+
+	return rm.m.Flush()
+}
+
+// Sync syncs the current file to stable storage.
+func (rm *RotatingManager) Sync() error {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	if rm.stopped {
+		return errors.New("rotating manager stopped")
+	}
+
+	return rm.m.Sync()
+}
+
+// Rotate forces a rotation, ignoring the policy and any pending retry, and
+// returns the path of the rotated file. Nothing is rotated while the current
+// file is empty and the returned path is then empty. ctx bounds the wait for
+// the ongoing write or rotation.
+func (rm *RotatingManager) Rotate(ctx context.Context) (string, error) {
+	if err := rm.lock(ctx); err != nil {
+		return "", err
+	}
+	defer rm.unlock()
+
+	if rm.stopped {
+		return "", errors.New("rotating manager stopped")
+	}
+
+	if rm.m.writes == 0 {
+		return "", nil
+	}
+
+	return rm.rotate(ReasonManual)
+}
+
+// Reopen reopens the current file path once it has been moved away, by
+// logrotate for instance, and returns it. The moved file is neither
+// finalized nor handed over. Nothing happens when the file was not moved.
+func (rm *RotatingManager) Reopen() (string, error) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	if rm.stopped {
+		return "", errors.New("rotating manager stopped")
+	}
+
+	old := rm.m
+	if old.info != nil {
+		if info, err := os.Stat(old.activePath); err == nil && os.SameFile(old.info, info) {
+			return old.path, nil
+		}
+	}
+
+	m, err := rm.openDecoratedManager(old.path, old.partition, time.Now())
+	if err != nil {
+		return "", &RotationError{Op: "open", Path: old.path, Err: err}
+	}
+
+	m.seq = old.seq
+	rm.m = m
+	rm.wakeUp()
+
+	if len(rm.acks) > 0 {
+		rm.resolveAcks(old.Sync())
+	}
+
+	if err := rm.closeManager(old); err != nil {
+		return m.path, &RotationError{Op: "close", Path: old.path, Err: err}
+	}
+
+	return m.path, nil
+}
+
+// lock acquires rm.mtx unless ctx is done first.
+func (rm *RotatingManager) lock(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	locked := make(chan struct{})
+
+	go func() {
+		rm.mtx.Lock()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-locked
+			rm.mtx.Unlock()
+		}()
+
+		return ctx.Err()
+	}
+}
+
 func (rm *RotatingManager) Close() error {
+	return rm.CloseContext(context.Background())
+}
+
+// CloseContext closes the RotatingManager and waits for the rotated file
+// handlers to drain their queue, or for ctx to be done. Handlers still
+// running when ctx is done are left to finish in the background.
+func (rm *RotatingManager) CloseContext(ctx context.Context) error {
 	rm.cancel()
 	<-rm.done
+	<-rm.committed
+
+	if rm.signalsDone != nil {
+		<-rm.signalsDone
+	}
+
+	// Prunes still running must not see the last file before it is finalized,
+	// later ones read stopped under the lock. Writes still waiting for the
+	// lock are refused.
+	rm.background.Wait()
+	rm.mtx.Lock()
 	rm.stopped = true
 
-	if err := rm.m.Close(); err != nil {
-		return errors.Wrap(err, "unable to close manager")
+	if len(rm.acks) > 0 {
+		rm.resolveAcks(rm.m.Sync())
 	}
 
-	rm.notifyRotationHandler()
-	return nil
+	// Like in rotate, a file that failed to close is still handed over.
+	cerr := rm.closeManager(rm.m)
+
+	if err := rm.finalize(rm.m); err != nil {
+		rm.mtx.Unlock()
+		return errors.Wrap(err, "unable to finalize file")
+	}
+
+	rm.handOver(rotatedFile(rm.m, ReasonClose))
+	rm.unlock()
+
+	rm.stopCompressors()
+
+	// Pruning once the handlers drained lets the retention see every file
+	// they were done with.
+	err := rm.stopHandlers(ctx)
+	rm.prune()
+	rm.background.Wait()
+
+	if jerr := rm.closeJournal(); err == nil {
+		err = jerr
+	}
+
+	if cerr != nil {
+		return errors.Wrap(cerr, "unable to close manager")
+	}
+
+	return err
+}
+
+// stopWorkers stops the compressors and handlers started by a constructor
+// that failed.
+func (rm *RotatingManager) stopWorkers() {
+	rm.stopCompressors()
+	_ = rm.stopHandlers(context.Background())
+	_ = rm.closeJournal()
 }
 
 func (rm *RotatingManager) start() {
-	rm.mtx.Lock()
-	defer rm.mtx.Unlock()
 	ctx, cancel := context.WithCancel(context.Background())
-	ticker := time.NewTicker(rm.rotateTime)
 
 	go func() {
-		defer ticker.Stop()
-
-		for range chans.OrDoneTimeTime(ctx, ticker.C) {
+		for {
 			rm.mtx.Lock()
-			if rm.m.WrittenBytes() > 0 {
-				rm.rotate()
-			}
+			timer := time.NewTimer(rm.nextTick(time.Now()))
 			rm.mtx.Unlock()
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				rm.done <- true
+				return
+			case <-rm.wake:
+				timer.Stop()
+			case now := <-timer.C:
+				rm.tick(now)
+			}
 		}
+	}()
 
-		rm.done <- true
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				rm.committed <- true
+				return
+			case <-rm.commits:
+				rm.commit()
+			}
+		}
 	}()
 
 	rm.ctx = ctx
 	rm.cancel = cancel
-	rm.rotateTicker = ticker
 }
 
-func (rm *RotatingManager) rotate() {
-	if err := rm.m.Close(); err != nil {
-		panic(err)
+// requestCommit asks the committer to sync the pending acknowledgements,
+// requests made while a sync is running are served by the next one.
+func (rm *RotatingManager) requestCommit() {
+	select {
+	case rm.commits <- struct{}{}:
+	default:
 	}
+}
 
-	m, err := newDecoratedManager(rm.path, rm.prefix, rm.factory)
+// commit syncs the current file and acknowledges every write it holds. Only
+// the flush happens under the lock when the manager can sync the flushed data
+// on its own, writes then go on during the fsync.
+func (rm *RotatingManager) commit() {
+	rm.mtx.Lock()
+
+	dm, acks := rm.m, rm.acks
+	s, ok := dm.FileManager.(flushedSyncer)
+	if len(acks) == 0 || !ok {
+		if len(acks) > 0 {
+			rm.resolveAcks(dm.Sync())
+		}
+
+		rm.mtx.Unlock()
+		return
+	}
+
+	rm.acks = nil
+	if err := dm.Flush(); err != nil {
+		resolveAcks(acks, err)
+		rm.mtx.Unlock()
+		return
+	}
+
+	dm.syncs.Add(1)
+	rm.mtx.Unlock()
+
+	err := s.syncFlushed()
+	dm.syncs.Done()
+	resolveAcks(acks, err)
+}
+
+func (rm *RotatingManager) resolveAcks(err error) {
+	resolveAcks(rm.acks, err)
+	rm.acks = nil
+}
+
+func resolveAcks(acks []chan error, err error) {
 	if err != nil {
-		panic(err)
+		err = errors.Wrap(err, "unable to sync file")
 	}
 
-	rm.notifyRotationHandler()
+	for _, ack := range acks {
+		ack <- err
+	}
+}
 
+// tick consults the policy without any write, empty files are never rotated.
+func (rm *RotatingManager) tick(now time.Time) {
+	rm.mtx.Lock()
+	defer rm.unlock()
+
+	if rm.m.writes == 0 {
+		return
+	}
+
+	if rm.degraded {
+		rm.tryRotate(rm.retryReason)
+		return
+	}
+
+	if stats := rm.m.stats(); rm.policy.ShouldRotate(stats, now) {
+		rm.tryRotate(rm.policyReason(stats, now))
+	}
+}
+
+// nextTick returns how long to wait before the next tick. When nothing is
+// scheduled the ticker sleeps until woken up by a rotation.
+func (rm *RotatingManager) nextTick(now time.Time) time.Duration {
+	next := nextCheck(rm.policy, rm.m.cachedStats())
+
+	if rm.degraded && (next.IsZero() || rm.retryAt.Before(next)) {
+		next = rm.retryAt
+	}
+
+	if next.IsZero() || !next.After(now) {
+		return time.Duration(math.MaxInt64)
+	}
+
+	return next.Sub(now)
+}
+
+// wakeUp makes the ticker compute its next tick again.
+func (rm *RotatingManager) wakeUp() {
+	select {
+	case rm.wake <- struct{}{}:
+	default:
+	}
+}
+
+// tryRotate rotates unless a previous rotation failed and its retry is not
+// due yet.
+func (rm *RotatingManager) tryRotate(reason RotationReason) {
+	if rm.degraded && time.Now().Before(rm.retryAt) {
+		return
+	}
+
+	_, _ = rm.rotate(reason)
+}
+
+// rotate opens the next file before closing the current one so that a failed
+// open leaves the manager with a usable file. It returns the path of the
+// rotated file, empty when the file was dropped. A file that failed to close
+// is still handed over, along with the error.
+func (rm *RotatingManager) rotate(reason RotationReason) (string, error) {
+	m, err := rm.newDecoratedManager()
+	if err != nil {
+		rerr := &RotationError{Op: "open", Err: err}
+		rm.retryReason = reason
+		rm.degrade(rerr)
+
+		return "", rerr
+	}
+
+	old := rm.m
 	rm.m = m
-	rm.rotateTicker.Reset(rm.rotateTime)
+	rm.degraded = false
+	rm.retries = 0
+	rm.wakeUp()
+
+	// Pending acknowledgements belong to the old file.
+	if len(rm.acks) > 0 {
+		rm.resolveAcks(old.Sync())
+	}
+
+	// The data of a file that failed to close may still be on disk, it is
+	// handed over rather than left behind.
+	var cerr error
+	if err := rm.closeManager(old); err != nil {
+		cerr = &RotationError{Op: "close", Path: old.path, Err: err}
+		rm.reportError(cerr)
+	}
+
+	if old.writes == 0 {
+		// Files are only rotated empty when they outlived their period before
+		// receiving anything, they are dropped rather than handed over.
+		_ = os.Remove(old.activePath)
+		return "", cerr
+	}
+
+	if err := rm.finalize(old); err != nil {
+		rerr := &RotationError{Op: "finalize", Path: old.path, Err: err}
+		rm.reportError(rerr)
+
+		return "", rerr
+	}
+
+	f := rotatedFile(old, reason)
+	rm.handOver(f)
+	rm.prune()
+
+	return old.path, cerr
+}
+
+func (rm *RotatingManager) degrade(err error) {
+	rm.degraded = true
+	rm.retryAt = time.Now().Add(rm.backoff.Duration(rm.retries))
+	rm.retries++
+	rm.wakeUp()
+
+	rm.reportError(err)
 }
 
-func (rm *RotatingManager) notifyRotationHandler() {
-	if rm.rotatedFileHandler != nil {
-		rm.rotatedFileHandler(rm.m.path)
+// reportError calls the error handler, errMtx serializes the calls made from
+// the writers and from the background workers.
+func (rm *RotatingManager) reportError(err error) {
+	rm.errMtx.Lock()
+	defer rm.errMtx.Unlock()
+
+	if rm.errorHandler != nil {
+		rm.errorHandler(err)
+	}
+}
+
+// handOver journals a finalized file, it is dispatched once rm.mtx is
+// released.
+func (rm *RotatingManager) handOver(f RotatedFile) {
+	rm.unhandled.add(f.Path)
+	rm.journalAdd(f.Path)
+	rm.handOvers = append(rm.handOvers, f)
+}
+
+// unlock releases rm.mtx and dispatches the files handed over meanwhile, so
+// that writers waiting for a full queue do not hold the lock.
+func (rm *RotatingManager) unlock() {
+	pending := len(rm.handOvers) > 0
+	rm.mtx.Unlock()
+
+	if pending {
+		rm.dispatchHandOvers()
+	}
+}
+
+// dispatchHandOvers dispatches the files handed over, in order.
+func (rm *RotatingManager) dispatchHandOvers() {
+	rm.dispatchMtx.Lock()
+	defer rm.dispatchMtx.Unlock()
+
+	rm.mtx.Lock()
+	files := rm.handOvers
+	rm.handOvers = nil
+	rm.mtx.Unlock()
+
+	for _, f := range files {
+		rm.dispatch(f)
+	}
+}
+
+// dispatch passes a file to the compressors, or directly to the handler when
+// it needs no compression. The compressors write the sidecar of the files
+// they compress.
+func (rm *RotatingManager) dispatch(f RotatedFile) {
+	if rm.compression != nil && !strings.HasSuffix(f.Path, rm.compression.Codec.Extension()) {
+		rm.compressions <- f
+		return
+	}
+
+	rm.writeSidecar(f)
+	rm.notifyRotationHandler(f)
+}
+
+// initNames gathers what the name strategy may use, sequences resume after
+// the highest one found in the directory.
+func (rm *RotatingManager) initNames() error {
+	rm.nameInfo.Prefix = rm.prefix
+	rm.nameInfo.PID = os.Getpid()
+	rm.nameInfo.Hostname, _ = os.Hostname()
+
+	parser, ok := rm.names.(SequenceParser)
+	if !ok {
+		return nil
 	}
+
+	return rm.walkFiles(func(path string, info os.FileInfo) {
+		name := strings.TrimSuffix(info.Name(), rm.inProgressSuffix)
+
+		if seq, ok := parser.ParseSeq(rm.prefix, name); ok && seq >= rm.nameInfo.Seq {
+			rm.nameInfo.Seq = seq + 1
+		}
+	})
 }
 
-func newDecoratedManager(path, prefix string, f ManagerFactory) (*decoratedManager, error) {
-	fn := NewRandFileName(path, prefix)
+// walkFiles calls fn for every regular file of the directory, partitions
+// included, except the journal and the checksum sidecars.
+func (rm *RotatingManager) walkFiles(fn func(path string, info os.FileInfo)) error {
+	if rm.partitioner == nil {
+		files, err := ioutil.ReadDir(rm.path)
+		if err != nil {
+			return errors.Wrap(err, "unable to read directory")
+		}
+
+		for _, f := range files {
+			if f.Mode().IsRegular() && !rm.isJournal(filepath.Join(rm.path, f.Name())) && !rm.isSidecar(f.Name()) {
+				fn(filepath.Join(rm.path, f.Name()), f)
+			}
+		}
+
+		return nil
+	}
+
+	err := filepath.Walk(rm.path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 
-	m, err := f(fn)
+		if info.Mode().IsRegular() && !rm.isJournal(path) && !rm.isSidecar(path) {
+			fn(path, info)
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "unable to walk directory")
+}
+
+func (rm *RotatingManager) newDecoratedManager() (*decoratedManager, error) {
+	now := time.Now()
+	info := rm.nameInfo
+	info.Time = now.UTC()
+
+	name, err := rm.names.Name(info)
+	if err != nil {
+		return nil, errors.Wrap(err, "name strategy failed")
+	}
+
+	dir, partition := rm.path, ""
+	if rm.partitioner != nil {
+		at := rm.partitionAt
+		if at.IsZero() {
+			at = now
+		}
+
+		partition = rm.partitioner.Partition(at)
+		dir = filepath.Join(rm.path, partition)
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, errors.Wrap(err, "unable to create partition")
+		}
+	}
+
+	fn := filepath.Join(dir, name)
+
+	// Name strategies may give a name twice, a coarse strftime pattern does
+	// within its period, the factory would then truncate the file.
+	for _, path := range []string{fn, fn + rm.inProgressSuffix} {
+		if _, err := os.Stat(path); err == nil {
+			return nil, errors.Errorf("file %s already exists", path)
+		}
+	}
+
+	dm, err := rm.openDecoratedManager(fn, partition, now)
+	if err != nil {
+		return nil, err
+	}
+
+	dm.seq = info.Seq
+	rm.nameInfo.Seq++
+
+	return dm, nil
+}
+
+// openDecoratedManager opens the file named fn through the factory.
+func (rm *RotatingManager) openDecoratedManager(fn, partition string, now time.Time) (*decoratedManager, error) {
+	m, err := rm.factory(fn + rm.inProgressSuffix)
 	if err != nil {
 		return nil, errors.Wrap(err, "manager factory failed")
 	}
 
-	return &decoratedManager{
+	dm := &decoratedManager{
 		FileManager: m,
 		path:        fn,
-	}, nil
+		activePath:  fn + rm.inProgressSuffix,
+		partition:   partition,
+		openedAt:    now,
+	}
+
+	if err := rm.openHooks(dm); err != nil {
+		_ = m.Close()
+		_ = os.Remove(dm.activePath)
+
+		return nil, err
+	}
+
+	// Factories may not create any file, Reopen then always reopens.
+	dm.info, _ = os.Stat(dm.activePath)
+
+	return dm, nil
+}
+
+// newManager is the default factory, it creates Managers with the options
+// given through WithManagerOptions.
+func (rm *RotatingManager) newManager(path string) (contracts.FileManager, error) {
+	return NewManager(path, rm.managerOptions...)
+}
+
+// writeParts writes the parts of a single record, it counts as one write.
+func (dm *decoratedManager) writeParts(parts [][]byte) (int, error) {
+	total := 0
+
+	for _, b := range parts {
+		w, err := dm.FileManager.Write(b)
+		total += w
+
+		if err != nil {
+			return total, err
+		}
+	}
+
+	dm.writes++
+	dm.lastWriteAt = time.Now()
+
+	return total, nil
+}
+
+// stats refreshes the written bytes from the managed file.
+func (dm *decoratedManager) stats() FileStats {
+	dm.written = dm.FileManager.WrittenBytes()
+	dm.disk = dm.written
+
+	if dm.written >= dm.hookBytes {
+		dm.written -= dm.hookBytes
+	}
+
+	if d, ok := dm.FileManager.(diskSizer); ok {
+		dm.disk = d.DiskBytes()
+	}
+
+	return dm.cachedStats()
+}
+
+// cachedStats uses the written bytes as of the last call to stats.
+func (dm *decoratedManager) cachedStats() FileStats {
+	return FileStats{
+		Path:         dm.path,
+		OpenedAt:     dm.openedAt,
+		LastWriteAt:  dm.lastWriteAt,
+		WrittenBytes: dm.written,
+		DiskBytes:    dm.disk,
+		Writes:       dm.writes,
+	}
 }
@@ -1,6 +1,7 @@
 package gofile
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/golang/mock/gomock"
@@ -9,8 +10,10 @@ import (
 	m "github.com/paulhenri-l/gofile/mocks/contracts"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -75,11 +78,204 @@ func TestRotationEveryNBytesRefreshedWhenRotated(t *testing.T) {
 	assert.Equal(t, m1, m2)
 }
 
+func TestRotationWithPolicy(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(2), newTestRealManagerFactory(),
+	)
+	m1 := rm.m
+
+	_, _ = rm.Write([]byte("hello"))
+	m2 := rm.m
+	_, _ = rm.Write([]byte("hello"))
+	m3 := rm.m
+
+	assert.Equal(t, m1, m2)
+	assert.NotEqual(t, m2, m3)
+}
+
+func TestRotationWithIdlePolicy(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", IdlePolicy(time.Millisecond*5), newTestRealManagerFactory(),
+	)
+	_, _ = rm.Write([]byte("hello"))
+
+	rm.mtx.Lock()
+	m1 := rm.m
+	rm.mtx.Unlock()
+
+	time.Sleep(time.Millisecond * 50)
+
+	rm.mtx.Lock()
+	m2 := rm.m
+	rm.mtx.Unlock()
+
+	assert.NotEqual(t, m1, m2)
+}
+
+func TestScheduledRotationHappensBeforeWrite(t *testing.T) {
+	var rotated string
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", AlignedPolicy(time.Hour, time.UTC), nil,
+	)
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		rotated = f.Path
+	})
+
+	_, _ = rm.Write([]byte("hello"))
+	m1 := rm.m
+	m1.openedAt = m1.openedAt.Add(-time.Hour)
+	_, _ = rm.Write([]byte("world"))
+	rm.Close()
+
+	c, _ := ioutil.ReadFile(rotated)
+
+	assert.NotEqual(t, m1, rm.m)
+	assert.Equal(t, "world", string(c))
+}
+
+func TestExpiredEmptyFileIsDropped(t *testing.T) {
+	var called bool
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", AlignedPolicy(time.Hour, time.UTC), nil,
+	)
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		called = true
+	})
+
+	m1 := rm.m
+	m1.openedAt = m1.openedAt.Add(-time.Hour)
+	_, _ = rm.Write([]byte("hello"))
+
+	_, err := os.Stat(m1.path)
+
+	assert.NotEqual(t, m1, rm.m)
+	assert.True(t, os.IsNotExist(err))
+	assert.False(t, called)
+}
+
+func TestRotatingManagerNameStrategy(t *testing.T) {
+	tmp := t.TempDir()
+	rm, _ := NewRotatingManager(
+		tmp, "events.", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(3, ".log")),
+	)
+
+	_, _ = rm.Write([]byte("hello"))
+	rm.Close()
+
+	_, err1 := os.Stat(tmp + "/events.000.log")
+	_, err2 := os.Stat(tmp + "/events.001.log")
+
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+}
+
+func TestRotatingManagerDoesNotReuseNames(t *testing.T) {
+	var reported error
+	var handled []string
+	tmp := t.TempDir()
+	rm, _ := NewRotatingManagerWithPolicy(
+		tmp, "events.", SizePolicy(5), nil,
+		WithNameStrategy(StrftimeNameStrategy("%Y%m%d%H.log")),
+		WithErrorHandler(func(err error) {
+			reported = err
+		}),
+	)
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		handled = append(handled, f.Path)
+	})
+	path := rm.m.path
+
+	_, _ = rm.Write([]byte("AAAAA"))
+	_, _ = rm.Write([]byte("BBBBB"))
+	_, _ = rm.Write([]byte("CC"))
+	rm.Close()
+	c, _ := ioutil.ReadFile(path)
+
+	assert.Error(t, reported)
+	assert.Equal(t, []string{path}, handled)
+	assert.Equal(t, "AAAAABBBBBCC", string(c))
+}
+
+func TestRotatingManagerSequenceResumes(t *testing.T) {
+	tmp := t.TempDir()
+	_ = ioutil.WriteFile(tmp+"/events.041.log", []byte("old"), 0644)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events.", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(3, ".log")),
+	)
+	rm.Close()
+
+	assert.Equal(t, tmp+"/events.042.log", rm.m.path)
+}
+
+func TestRotatingManagerBrokenNameStrategy(t *testing.T) {
+	broken := NameStrategyFunc(func(info NameInfo) (string, error) {
+		return "", errors.New("I am broken")
+	})
+
+	_, err := NewRotatingManager(
+		t.TempDir(), "events.", time.Second*100, 5, WithNameStrategy(broken),
+	)
+
+	assert.Error(t, err)
+}
+
+func TestRotatingManagerPartitionsFiles(t *testing.T) {
+	tmp := t.TempDir()
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 5,
+		WithPartitioner(HivePartitioner(true, time.UTC)),
+	)
+	rm.Close()
+
+	dir := filepath.Join(tmp, HivePartitioner(true, time.UTC).Partition(rm.m.openedAt))
+
+	assert.Equal(t, dir, filepath.Dir(rm.m.path))
+}
+
+func TestRotatingManagerPartitionsByRecordTime(t *testing.T) {
+	tmp := t.TempDir()
+	p := HivePartitioner(true, time.UTC)
+	rm, _ := NewRotatingManager(
+		tmp, "events_", time.Second*100, 1000, WithPartitioner(p),
+	)
+	yesterday := time.Now().Add(-24 * time.Hour)
+
+	_, _ = rm.Write([]byte("now"))
+	m1 := rm.m
+	_, _ = rm.WriteAt(yesterday, []byte("yesterday"))
+	m2 := rm.m
+	rm.Close()
+
+	c, _ := ioutil.ReadFile(m2.path)
+
+	assert.NotEqual(t, m1, m2)
+	assert.Equal(t, filepath.Join(tmp, p.Partition(yesterday)), filepath.Dir(m2.path))
+	assert.Equal(t, "yesterday", string(c))
+}
+
+func TestRotatingManagerSequenceResumesAcrossPartitions(t *testing.T) {
+	tmp := t.TempDir()
+	_ = os.MkdirAll(tmp+"/dt=2026-01-01", 0755)
+	_ = ioutil.WriteFile(tmp+"/dt=2026-01-01/events.041.log", []byte("old"), 0644)
+
+	rm, _ := NewRotatingManager(
+		tmp, "events.", time.Second*100, 5,
+		WithNameStrategy(SequenceNameStrategy(3, ".log")),
+		WithPartitioner(HivePartitioner(false, time.UTC)),
+	)
+	rm.Close()
+
+	assert.Equal(t, "events.042.log", filepath.Base(rm.m.path))
+}
+
 func TestHandlerCalledOnEveryRotation(t *testing.T) {
 	var called bool
 	var receivedPath string
-	cb := func(path string) {
-		receivedPath = path
+	cb := func(f RotatedFile) {
+		receivedPath = f.Path
 		called = true
 	}
 
@@ -141,7 +337,7 @@ func TestClosedWhenRotating(t *testing.T) {
 	f, _, m := newTestManagerFactory(t)
 
 	m.EXPECT().Write(gomock.Eq(b)).Return(5, nil)
-	m.EXPECT().WrittenBytes().Return(uint64(5))
+	m.EXPECT().WrittenBytes().Return(uint64(5)).Times(2)
 	m.EXPECT().Close()
 
 	rm, _ := NewRotatingManagerWithFactory(
@@ -162,39 +358,114 @@ func TestWrittenBytesAreKeptBetweenRotations(t *testing.T) {
 }
 
 func TestRotatingManager_RotationBrokenClose(t *testing.T) {
-	assert.Panics(t, func() {
-		b := []byte("hello")
-		f, _, m := newTestManagerFactory(t)
+	var reported error
+	var handled string
+	b := []byte("hello")
+	f, _, m := newTestManagerFactory(t)
 
-		m.EXPECT().Write(gomock.Eq(b)).Return(5, nil)
-		m.EXPECT().WrittenBytes().Return(uint64(5))
-		m.EXPECT().Close().Return(errors.New("I am broken"))
+	m.EXPECT().Write(gomock.Eq(b)).Return(5, nil)
+	m.EXPECT().WrittenBytes().Return(uint64(5)).Times(2)
+	m.EXPECT().Close().Return(errors.New("I am broken"))
 
-		rm, _ := NewRotatingManagerWithFactory(
-			t.TempDir(), "events_", time.Second*100, 5, f,
-		)
+	rm, _ := NewRotatingManagerWithFactory(
+		t.TempDir(), "events_", time.Second*100, 5, f,
+	)
+	path := rm.m.path
+	rm.WithErrorHandler(func(err error) {
+		reported = err
+	})
+	rm.WithRotatedFileHandler(func(f RotatedFile) {
+		handled = f.Path
+	})
 
+	assert.NotPanics(t, func() {
 		rm.Write(b)
 	})
+
+	var rotationErr *RotationError
+	assert.True(t, errors.As(reported, &rotationErr))
+	assert.Equal(t, "close", rotationErr.Op)
+	assert.False(t, rm.Degraded())
+	assert.Equal(t, path, handled)
 }
 
 func TestRotatingManager_RotationBrokenFactory(t *testing.T) {
-	assert.Panics(t, func() {
-		b := []byte("hello")
-		f, _, m := newTestManagerFactory(t)
+	var reported error
+	b := []byte("hello")
+	f, _, m := newTestManagerFactory(t)
 
-		m.EXPECT().Write(gomock.Eq(b)).Return(5, nil)
-		m.EXPECT().WrittenBytes().Return(uint64(5))
-		m.EXPECT().Close()
+	m.EXPECT().Write(gomock.Eq(b)).Return(5, nil)
+	m.EXPECT().WrittenBytes().Return(uint64(5))
 
-		rm, _ := NewRotatingManagerWithFactory(
-			t.TempDir(), "events_", time.Second*100, 5, f,
-		)
+	rm, _ := NewRotatingManagerWithFactory(
+		t.TempDir(), "events_", time.Second*100, 5, f,
+	)
+	rm.WithErrorHandler(func(err error) {
+		reported = err
+	})
 
-		rm.factory = newBrokenManagerFactory()
+	rm.factory = newBrokenManagerFactory()
 
+	assert.NotPanics(t, func() {
 		rm.Write(b)
 	})
+
+	var rotationErr *RotationError
+	assert.True(t, errors.As(reported, &rotationErr))
+	assert.Equal(t, "open", rotationErr.Op)
+	assert.True(t, rm.Degraded())
+}
+
+func TestRotatingManager_DegradedKeepsWritingToOldFile(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 5)
+	rm.WithRetryBackoff(Backoff{Initial: time.Hour})
+	factory := rm.factory
+	m1 := rm.m
+
+	rm.factory = newBrokenManagerFactory()
+	_, _ = rm.Write([]byte("hello"))
+	w, err := rm.Write([]byte("hello"))
+
+	assert.Equal(t, 5, w)
+	assert.NoError(t, err)
+	assert.Equal(t, m1, rm.m)
+	assert.Equal(t, uint64(10), rm.m.WrittenBytes())
+
+	rm.factory = factory
+	rm.retryAt = time.Now()
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.NotEqual(t, m1, rm.m)
+	assert.False(t, rm.Degraded())
+}
+
+func TestRotatingManager_DegradedRefusesWrites(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 5)
+	rm.WithDegradedMode(RefuseWrites)
+	rm.WithRetryBackoff(Backoff{Initial: time.Hour})
+
+	rm.factory = newBrokenManagerFactory()
+	_, _ = rm.Write([]byte("hello"))
+	w, err := rm.Write([]byte("hello"))
+
+	assert.Equal(t, 0, w)
+	assert.True(t, errors.Is(err, ErrDegraded))
+}
+
+func TestRotatingManager_DegradedRetriesWithBackoff(t *testing.T) {
+	var reported int
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 5)
+	rm.WithRetryBackoff(Backoff{Initial: time.Hour})
+	rm.WithErrorHandler(func(err error) {
+		reported++
+	})
+
+	rm.factory = newBrokenManagerFactory()
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("hello"))
+	_, _ = rm.Write([]byte("hello"))
+
+	assert.Equal(t, 1, reported)
 }
 
 func TestCannotWriteOnStoppedRotatingManager(t *testing.T) {
@@ -210,20 +481,243 @@ func TestCannotWriteOnStoppedRotatingManager(t *testing.T) {
 func TestClose(t *testing.T) {
 	f, _, m := newTestManagerFactory(t)
 
+	m.EXPECT().WrittenBytes()
+	m.EXPECT().Close()
+
+	rm, _ := NewRotatingManagerWithFactory(
+		t.TempDir(), "events_", time.Second*100, 5, f,
+	)
+
+	rm.Close()
+}
+
+func TestSyncIsForwarded(t *testing.T) {
+	f, _, m := newTestManagerFactory(t)
+
+	m.EXPECT().Flush()
+	m.EXPECT().Sync()
+	m.EXPECT().WrittenBytes()
 	m.EXPECT().Close()
 
 	rm, _ := NewRotatingManagerWithFactory(
 		t.TempDir(), "events_", time.Second*100, 5, f,
 	)
 
+	assert.NoError(t, rm.Flush())
+	assert.NoError(t, rm.Sync())
+	rm.Close()
+	assert.Error(t, rm.Sync())
+}
+
+func TestWriteSync(t *testing.T) {
+	var syncs int32
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(1000), newSyncCountingManagerFactory(&syncs),
+	)
+	defer rm.Close()
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- rm.WriteSync(context.Background(), []byte("hello"))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	c, _ := ioutil.ReadFile(rm.m.activePath)
+
+	assert.Len(t, c, 100)
+	assert.True(t, atomic.LoadInt32(&syncs) >= 1)
+	assert.True(t, atomic.LoadInt32(&syncs) <= 20)
+}
+
+func TestWriteSyncRacingRotationsAndClose(t *testing.T) {
+	var handled int32
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(50), nil,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			atomic.AddInt32(&handled, int32(f.WrittenBytes))
+		}),
+	)
+
+	wg := sync.WaitGroup{}
+	var synced int32
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rm.WriteSync(context.Background(), []byte("hello")) == nil {
+				atomic.AddInt32(&synced, 5)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond)
+	rm.Close()
+	wg.Wait()
+
+	assert.True(t, atomic.LoadInt32(&handled) >= atomic.LoadInt32(&synced))
+}
+
+func TestWriteAckResolvedByRotation(t *testing.T) {
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", CountPolicy(1), nil,
+	)
+	defer rm.Close()
+
+	rm.mtx.Lock()
+	path := rm.m.path
+	rm.mtx.Unlock()
+
+	ack := rm.WriteAck([]byte("hello"))
+
+	select {
+	case err := <-ack:
+		c, _ := ioutil.ReadFile(path)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(c))
+	default:
+		t.Fatal("ack not resolved by rotation")
+	}
+}
+
+func TestWriteSyncWithDoneContext(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 1000)
+	defer rm.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := rm.WriteSync(ctx, []byte("hello"))
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, uint64(0), rm.WrittenBytes())
+}
+
+func TestWriteAckAfterClose(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 1000)
+	rm.Close()
+
+	assert.Error(t, <-rm.WriteAck([]byte("hello")))
+}
+
+func TestRotate(t *testing.T) {
+	var handled string
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled = f.Path
+		}),
+	)
+	defer rm.Close()
+	path := rm.m.path
+
+	_, _ = rm.Write([]byte("hello"))
+	rotated, err := rm.Rotate(context.Background())
+	c, _ := ioutil.ReadFile(rotated)
+
+	assert.NoError(t, err)
+	assert.Equal(t, path, rotated)
+	assert.Equal(t, path, handled)
+	assert.Equal(t, "hello", string(c))
+	assert.NotEqual(t, path, rm.m.path)
+}
+
+func TestRotateEmptyFile(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 1000)
+	defer rm.Close()
+	m1 := rm.m
+
+	rotated, err := rm.Rotate(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", rotated)
+	assert.Equal(t, m1, rm.m)
+}
+
+func TestRotateContext(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 1000)
+	defer rm.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+
+	rm.mtx.Lock()
+	_, err := rm.Rotate(ctx)
+	rm.mtx.Unlock()
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRotateBrokenFactory(t *testing.T) {
+	var broken bool
+	rm, _ := NewRotatingManagerWithPolicy(
+		t.TempDir(), "events_", SizePolicy(1000),
+		func(path string) (contracts.FileManager, error) {
+			if broken {
+				return nil, errors.New("I am broken")
+			}
+
+			return NewManager(path)
+		},
+	)
+	defer rm.Close()
+	_, _ = rm.Write([]byte("hello"))
+	broken = true
+
+	_, err := rm.Rotate(context.Background())
+	rerr := &RotationError{}
+
+	assert.True(t, errors.As(err, &rerr))
+	assert.Equal(t, "open", rerr.Op)
+	assert.True(t, rm.Degraded())
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	rm, _ := NewRotatingManager(dir, "events_", time.Second*100, 1000)
+	path := rm.m.path
+
+	_, _ = rm.Write([]byte("hello"))
+	_ = os.Rename(path, filepath.Join(dir, "moved"))
+	reopened, err := rm.Reopen()
+	_, _ = rm.Write([]byte("world"))
 	rm.Close()
+
+	moved, _ := ioutil.ReadFile(filepath.Join(dir, "moved"))
+	current, _ := ioutil.ReadFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, path, reopened)
+	assert.Equal(t, "hello", string(moved))
+	assert.Equal(t, "world", string(current))
+}
+
+func TestReopenWithoutMove(t *testing.T) {
+	rm, _ := NewRotatingManager(t.TempDir(), "events_", time.Second*100, 1000)
+	defer rm.Close()
+	m1 := rm.m
+
+	_, _ = rm.Write([]byte("hello"))
+	reopened, err := rm.Reopen()
+
+	assert.NoError(t, err)
+	assert.Equal(t, m1.path, reopened)
+	assert.Equal(t, m1, rm.m)
 }
 
 func TestHandlerCalledOnClose(t *testing.T) {
 	var called bool
 	var receivedPath string
-	cb := func(path string) {
-		receivedPath = path
+	cb := func(f RotatedFile) {
+		receivedPath = f.Path
 		called = true
 	}
 
@@ -240,6 +734,7 @@ func TestHandlerCalledOnClose(t *testing.T) {
 func TestRotatingManager_Stop_CloseError(t *testing.T) {
 	f, _, m := newTestManagerFactory(t)
 
+	m.EXPECT().WrittenBytes()
 	m.EXPECT().Close().Return(errors.New("I am broken"))
 
 	rm, _ := NewRotatingManagerWithFactory(
@@ -281,6 +776,34 @@ func newTestManagerFactory(t *testing.T) (ManagerFactory, *gomock.Controller, *m
 	return f, ctl, m
 }
 
+type syncCountingManager struct {
+	contracts.FileManager
+	syncs *int32
+}
+
+func (m *syncCountingManager) Sync() error {
+	atomic.AddInt32(m.syncs, 1)
+
+	return m.FileManager.Sync()
+}
+
+func newSyncCountingManagerFactory(syncs *int32) ManagerFactory {
+	return func(fileName string) (contracts.FileManager, error) {
+		m, err := NewManager(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		return &syncCountingManager{FileManager: m, syncs: syncs}, nil
+	}
+}
+
+func newTestRealManagerFactory() ManagerFactory {
+	return func(fileName string) (contracts.FileManager, error) {
+		return NewManager(fileName)
+	}
+}
+
 func newBrokenManagerFactory() ManagerFactory {
 	return func(fileName string) (contracts.FileManager, error) {
 		return nil, errors.New("I am broken")
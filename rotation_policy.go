@@ -0,0 +1,197 @@
+package gofile
+
+import (
+	"time"
+)
+
+// FileStats describes the file currently managed by a RotatingManager.
+type FileStats struct {
+	Path         string
+	OpenedAt     time.Time
+	LastWriteAt  time.Time
+	WrittenBytes uint64
+	// DiskBytes is the size of the file on disk, it differs from WrittenBytes
+	// when the manager compresses data on the fly.
+	DiskBytes uint64
+	Writes    uint64
+	// Checksum is the checksum of the bytes on disk so far, see WithChecksum.
+	// It is only set in the stats given to the OnClose hooks.
+	Checksum Checksum
+}
+
+// RotationPolicy decides when a RotatingManager should rotate its file, it is
+// consulted after every write. Policies that may become due while the file is
+// idle must implement ScheduledPolicy, others are never consulted between
+// writes.
+type RotationPolicy interface {
+	ShouldRotate(stats FileStats, now time.Time) bool
+}
+
+// ScheduledPolicy is implemented by policies that can become due without any
+// write happening. NextCheck returns when the policy should be consulted
+// again, the zero time meaning never.
+type ScheduledPolicy interface {
+	RotationPolicy
+	NextCheck(stats FileStats) time.Time
+}
+
+// RotationPolicyFunc adapts a function to the RotationPolicy interface.
+type RotationPolicyFunc func(stats FileStats, now time.Time) bool
+
+func (f RotationPolicyFunc) ShouldRotate(stats FileStats, now time.Time) bool {
+	return f(stats, now)
+}
+
+type sizePolicy struct {
+	maxBytes uint64
+}
+
+// SizePolicy rotates once at least maxBytes have been written to the file.
+func SizePolicy(maxBytes uint64) RotationPolicy {
+	return &sizePolicy{maxBytes: maxBytes}
+}
+
+func (p *sizePolicy) ShouldRotate(stats FileStats, _ time.Time) bool {
+	return stats.WrittenBytes > 0 && stats.WrittenBytes >= p.maxBytes
+}
+
+type diskSizePolicy struct {
+	maxBytes uint64
+}
+
+// DiskSizePolicy rotates once the file takes at least maxBytes on disk.
+func DiskSizePolicy(maxBytes uint64) RotationPolicy {
+	return &diskSizePolicy{maxBytes: maxBytes}
+}
+
+func (p *diskSizePolicy) ShouldRotate(stats FileStats, _ time.Time) bool {
+	return stats.DiskBytes > 0 && stats.DiskBytes >= p.maxBytes
+}
+
+type countPolicy struct {
+	maxWrites uint64
+}
+
+// CountPolicy rotates once maxWrites records have been written to the file.
+func CountPolicy(maxWrites uint64) RotationPolicy {
+	return &countPolicy{maxWrites: maxWrites}
+}
+
+func (p *countPolicy) ShouldRotate(stats FileStats, _ time.Time) bool {
+	return stats.Writes > 0 && stats.Writes >= p.maxWrites
+}
+
+type agePolicy struct {
+	maxAge time.Duration
+}
+
+// AgePolicy rotates files once they have been opened for maxAge.
+func AgePolicy(maxAge time.Duration) ScheduledPolicy {
+	return &agePolicy{maxAge: maxAge}
+}
+
+func (p *agePolicy) ShouldRotate(stats FileStats, now time.Time) bool {
+	return !now.Before(p.NextCheck(stats))
+}
+
+func (p *agePolicy) NextCheck(stats FileStats) time.Time {
+	return stats.OpenedAt.Add(p.maxAge)
+}
+
+type idlePolicy struct {
+	timeout time.Duration
+}
+
+// IdlePolicy rotates files that have not been written to for timeout.
+func IdlePolicy(timeout time.Duration) ScheduledPolicy {
+	return &idlePolicy{timeout: timeout}
+}
+
+func (p *idlePolicy) ShouldRotate(stats FileStats, now time.Time) bool {
+	return !now.Before(p.NextCheck(stats))
+}
+
+func (p *idlePolicy) NextCheck(stats FileStats) time.Time {
+	last := stats.LastWriteAt
+	if last.IsZero() {
+		last = stats.OpenedAt
+	}
+
+	return last.Add(p.timeout)
+}
+
+type anyPolicy struct {
+	policies []RotationPolicy
+}
+
+// AnyPolicy rotates as soon as one of the given policies wants to.
+func AnyPolicy(policies ...RotationPolicy) ScheduledPolicy {
+	return &anyPolicy{policies: policies}
+}
+
+func (p *anyPolicy) ShouldRotate(stats FileStats, now time.Time) bool {
+	for _, policy := range p.policies {
+		if policy.ShouldRotate(stats, now) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *anyPolicy) NextCheck(stats FileStats) time.Time {
+	var next time.Time
+
+	for _, policy := range p.policies {
+		t := nextCheck(policy, stats)
+
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+type allPolicy struct {
+	policies []RotationPolicy
+}
+
+// AllPolicy rotates only when every given policy wants to.
+func AllPolicy(policies ...RotationPolicy) ScheduledPolicy {
+	return &allPolicy{policies: policies}
+}
+
+func (p *allPolicy) ShouldRotate(stats FileStats, now time.Time) bool {
+	if len(p.policies) == 0 {
+		return false
+	}
+
+	for _, policy := range p.policies {
+		if !policy.ShouldRotate(stats, now) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *allPolicy) NextCheck(stats FileStats) time.Time {
+	var next time.Time
+
+	for _, policy := range p.policies {
+		if t := nextCheck(policy, stats); t.After(next) {
+			next = t
+		}
+	}
+
+	return next
+}
+
+func nextCheck(policy RotationPolicy, stats FileStats) time.Time {
+	if sp, ok := policy.(ScheduledPolicy); ok {
+		return sp.NextCheck(stats)
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,89 @@
+package gofile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizePolicy(t *testing.T) {
+	p := SizePolicy(5)
+	now := time.Now()
+
+	assert.False(t, p.ShouldRotate(FileStats{WrittenBytes: 4}, now))
+	assert.True(t, p.ShouldRotate(FileStats{WrittenBytes: 5}, now))
+}
+
+func TestSizePolicyNeverRotatesEmptyFiles(t *testing.T) {
+	assert.False(t, SizePolicy(0).ShouldRotate(FileStats{}, time.Now()))
+}
+
+func TestDiskSizePolicy(t *testing.T) {
+	p := DiskSizePolicy(5)
+	now := time.Now()
+
+	assert.False(t, p.ShouldRotate(FileStats{WrittenBytes: 10, DiskBytes: 4}, now))
+	assert.True(t, p.ShouldRotate(FileStats{WrittenBytes: 10, DiskBytes: 5}, now))
+}
+
+func TestCountPolicy(t *testing.T) {
+	p := CountPolicy(3)
+	now := time.Now()
+
+	assert.False(t, p.ShouldRotate(FileStats{Writes: 2}, now))
+	assert.True(t, p.ShouldRotate(FileStats{Writes: 3}, now))
+}
+
+func TestAgePolicy(t *testing.T) {
+	opened := time.Now()
+	p := AgePolicy(time.Minute)
+	stats := FileStats{OpenedAt: opened}
+
+	assert.False(t, p.ShouldRotate(stats, opened.Add(time.Second)))
+	assert.True(t, p.ShouldRotate(stats, opened.Add(time.Minute)))
+	assert.Equal(t, opened.Add(time.Minute), p.NextCheck(stats))
+}
+
+func TestIdlePolicy(t *testing.T) {
+	opened := time.Now()
+	p := IdlePolicy(time.Minute)
+	stats := FileStats{OpenedAt: opened, LastWriteAt: opened.Add(time.Minute)}
+
+	assert.False(t, p.ShouldRotate(stats, opened.Add(time.Minute+time.Second)))
+	assert.True(t, p.ShouldRotate(stats, opened.Add(2*time.Minute)))
+	assert.Equal(t, opened.Add(2*time.Minute), p.NextCheck(stats))
+}
+
+func TestAnyPolicy(t *testing.T) {
+	opened := time.Now()
+	p := AnyPolicy(SizePolicy(5), AgePolicy(time.Minute), AgePolicy(time.Second))
+	stats := FileStats{OpenedAt: opened, WrittenBytes: 1}
+
+	assert.False(t, p.ShouldRotate(stats, opened))
+	assert.True(t, p.ShouldRotate(FileStats{WrittenBytes: 5}, opened))
+	assert.True(t, p.ShouldRotate(stats, opened.Add(time.Second)))
+	assert.Equal(t, opened.Add(time.Second), p.NextCheck(stats))
+}
+
+func TestAllPolicy(t *testing.T) {
+	opened := time.Now()
+	p := AllPolicy(SizePolicy(5), AgePolicy(time.Minute))
+
+	assert.False(t, p.ShouldRotate(FileStats{OpenedAt: opened, WrittenBytes: 5}, opened))
+	assert.False(t, p.ShouldRotate(FileStats{OpenedAt: opened}, opened.Add(time.Minute)))
+	assert.True(t, p.ShouldRotate(FileStats{OpenedAt: opened, WrittenBytes: 5}, opened.Add(time.Minute)))
+	assert.Equal(t, opened.Add(time.Minute), p.NextCheck(FileStats{OpenedAt: opened}))
+}
+
+func TestEmptyAllPolicyNeverRotates(t *testing.T) {
+	assert.False(t, AllPolicy().ShouldRotate(FileStats{WrittenBytes: 5}, time.Now()))
+}
+
+func TestRotationPolicyFunc(t *testing.T) {
+	p := RotationPolicyFunc(func(stats FileStats, _ time.Time) bool {
+		return stats.Writes == 2
+	})
+
+	assert.True(t, p.ShouldRotate(FileStats{Writes: 2}, time.Now()))
+}
@@ -0,0 +1,246 @@
+package gofile
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule tells when rotations should happen on the wall clock.
+type Schedule interface {
+	// Next returns the first rotation time strictly after t.
+	Next(t time.Time) time.Time
+}
+
+type everySchedule struct {
+	interval time.Duration
+	loc      *time.Location
+}
+
+// Every returns a schedule firing on every multiple of interval since
+// midnight in loc, Every(time.Hour, time.UTC) fires at the top of every UTC
+// hour. The interval should evenly divide a day, the schedule always fires at
+// midnight.
+func Every(interval time.Duration, loc *time.Location) Schedule {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	return &everySchedule{interval: interval, loc: loc}
+}
+
+func (s *everySchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	y, m, d := t.Date()
+	midnight := time.Date(y, m, d, 0, 0, 0, 0, s.loc)
+	nextMidnight := time.Date(y, m, d+1, 0, 0, 0, 0, s.loc)
+
+	if s.interval <= 0 {
+		return nextMidnight
+	}
+
+	n := t.Sub(midnight)/s.interval + 1
+	next := midnight.Add(n * s.interval)
+
+	if next.After(nextMidnight) {
+		return nextMidnight
+	}
+
+	return next
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = []cronField{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, 0 and 7 are sunday
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+	loc                           *time.Location
+}
+
+// ParseSchedule parses a standard five fields cron expression (minute, hour,
+// day of month, month, day of week) evaluated in loc. Fields accept *, lists,
+// ranges and steps, the @hourly, @daily, @weekly, @monthly and @yearly
+// descriptors are supported as well.
+func ParseSchedule(spec string, loc *time.Location) (Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	if d, ok := cronDescriptors[strings.TrimSpace(spec)]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(cronFields) {
+		return nil, errors.Errorf("expected 5 fields in schedule %q", spec)
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid schedule %q", spec)
+		}
+
+		bits[i] = b
+	}
+
+	// Sunday can be written as 0 or 7.
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+
+	return &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+		loc:     loc,
+	}, nil
+}
+
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, errors.Errorf("invalid step in %q", part)
+			}
+
+			step = s
+			part = part[:i]
+		}
+
+		from, to := bounds.min, bounds.max
+		if part != "*" {
+			r := strings.SplitN(part, "-", 2)
+
+			f, err := strconv.Atoi(r[0])
+			if err != nil {
+				return 0, errors.Errorf("invalid value %q", part)
+			}
+
+			from, to = f, f
+			if len(r) == 2 {
+				if to, err = strconv.Atoi(r[1]); err != nil {
+					return 0, errors.Errorf("invalid range %q", part)
+				}
+			} else if step > 1 {
+				to = bounds.max
+			}
+		}
+
+		if from < bounds.min || to > bounds.max || from > to {
+			return 0, errors.Errorf("%q out of range [%d-%d]", part, bounds.min, bounds.max)
+		}
+
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.loc)
+	limit := t.Year() + 5
+
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, s.loc)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows cron semantics, when both the day of month and the day
+// of week are restricted either of them may match.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+type schedulePolicy struct {
+	schedule Schedule
+	mtx      sync.Mutex
+	openedAt time.Time
+	next     time.Time
+}
+
+// SchedulePolicy rotates files once the schedule fired after they were
+// opened. Rotations stay aligned on the schedule whatever else triggers them.
+func SchedulePolicy(s Schedule) ScheduledPolicy {
+	return &schedulePolicy{schedule: s}
+}
+
+// AlignedPolicy rotates files on every multiple of interval since midnight in
+// loc, AlignedPolicy(time.Hour, time.UTC) produces files covering whole UTC
+// hours.
+func AlignedPolicy(interval time.Duration, loc *time.Location) ScheduledPolicy {
+	return SchedulePolicy(Every(interval, loc))
+}
+
+func (p *schedulePolicy) ShouldRotate(stats FileStats, now time.Time) bool {
+	next := p.NextCheck(stats)
+
+	return !next.IsZero() && !now.Before(next)
+}
+
+func (p *schedulePolicy) NextCheck(stats FileStats) time.Time {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if !p.openedAt.Equal(stats.OpenedAt) || p.next.IsZero() {
+		p.openedAt = stats.OpenedAt
+		p.next = p.schedule.Next(stats.OpenedAt)
+	}
+
+	return p.next
+}
@@ -0,0 +1,96 @@
+package gofile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryHour(t *testing.T) {
+	s := Every(time.Hour, time.UTC)
+	now := time.Date(2026, 10, 17, 14, 20, 5, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+func TestEveryIsStrictlyAfter(t *testing.T) {
+	s := Every(time.Hour, time.UTC)
+	now := time.Date(2026, 10, 17, 14, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+func TestEveryDayInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	s := Every(24*time.Hour, loc)
+	now := time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 19, 0, 0, 0, 0, loc), s.Next(now))
+}
+
+func TestEveryRestartsAtMidnight(t *testing.T) {
+	s := Every(7*time.Hour, time.UTC)
+	now := time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+func TestParseScheduleHourly(t *testing.T) {
+	s, err := ParseSchedule("@hourly", time.UTC)
+	now := time.Date(2026, 10, 17, 14, 20, 0, 0, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+func TestParseScheduleStepsAndRanges(t *testing.T) {
+	s, err := ParseSchedule("*/15 9-17 * * 1-5", time.UTC)
+	friday := time.Date(2026, 10, 16, 17, 50, 0, 0, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), s.Next(friday))
+}
+
+func TestParseScheduleLists(t *testing.T) {
+	s, err := ParseSchedule("0 0,12 * * *", time.UTC)
+	now := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC), s.Next(now))
+}
+
+func TestParseScheduleSundayAsSeven(t *testing.T) {
+	s, err := ParseSchedule("0 0 * * 7", time.UTC)
+	saturday := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), s.Next(saturday))
+}
+
+func TestParseScheduleInLocation(t *testing.T) {
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	s, err := ParseSchedule("@daily", loc)
+	now := time.Date(2026, 10, 17, 1, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 17, 5, 0, 0, 0, time.UTC), s.Next(now).UTC())
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "a * * * *", "*/0 * * * *", "5-1 * * * *"} {
+		_, err := ParseSchedule(spec, time.UTC)
+
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestAlignedPolicy(t *testing.T) {
+	p := AlignedPolicy(time.Hour, time.UTC)
+	opened := time.Date(2026, 10, 17, 14, 20, 0, 0, time.UTC)
+	stats := FileStats{OpenedAt: opened}
+
+	assert.False(t, p.ShouldRotate(stats, opened.Add(39*time.Minute)))
+	assert.True(t, p.ShouldRotate(stats, opened.Add(40*time.Minute)))
+	assert.Equal(t, time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC), p.NextCheck(stats))
+}
@@ -0,0 +1,51 @@
+//go:build !windows
+// +build !windows
+
+package gofile
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals reopens the current file on SIGHUP, after logrotate moved it
+// for instance, and rotates it on SIGUSR1. Signals are handled until the
+// RotatingManager is closed.
+func (rm *RotatingManager) HandleSignals() {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+
+	if rm.signalsDone != nil {
+		return
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP, syscall.SIGUSR1)
+	rm.signalsDone = make(chan bool, 1)
+
+	go func() {
+		for {
+			select {
+			case <-rm.ctx.Done():
+				signal.Stop(sigs)
+				rm.signalsDone <- true
+				return
+			case sig := <-sigs:
+				rm.handleSignal(sig)
+			}
+		}
+	}()
+}
+
+func (rm *RotatingManager) handleSignal(sig os.Signal) {
+	switch sig {
+	case syscall.SIGHUP:
+		if _, err := rm.Reopen(); err != nil {
+			rm.reportError(err)
+		}
+	case syscall.SIGUSR1:
+		// Rotation errors are reported by the rotation itself.
+		_, _ = rm.Rotate(rm.ctx)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build !windows
+// +build !windows
+
+package gofile
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSIGUSR1Rotates(t *testing.T) {
+	handled := make(chan string, 2)
+	rm, _ := NewRotatingManager(
+		t.TempDir(), "events_", time.Second*100, 1000,
+		WithRotatedFileHandler(func(f RotatedFile) {
+			handled <- f.Path
+		}),
+	)
+	path := rm.m.path
+	rm.HandleSignals()
+
+	_, _ = rm.Write([]byte("hello"))
+	_ = syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case rotated := <-handled:
+		assert.Equal(t, path, rotated)
+	case <-time.After(time.Second):
+		t.Fatal("file not rotated")
+	}
+
+	rm.Close()
+}
+
+func TestSIGHUPReopens(t *testing.T) {
+	dir := t.TempDir()
+	rm, _ := NewRotatingManager(dir, "events_", time.Second*100, 1000)
+	path := rm.m.path
+	rm.HandleSignals()
+
+	_ = os.Rename(path, filepath.Join(dir, "moved"))
+	_ = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, time.Millisecond*5)
+
+	rm.Close()
+}